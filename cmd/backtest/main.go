@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kraken-dca-bot/internal/backtest"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/marketdata"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var configPath string
+var dataPath string
+var storePath string
+var interval int
+var from string
+var to string
+var funds float64
+var verbose bool
+
+func init() {
+	flag.StringVar(&configPath, "config", "config.yaml", "the configuration file path with the DCA strategy")
+	flag.StringVar(&dataPath, "data", "data", "the directory holding a <pair>.csv candles file per traded pair")
+	flag.StringVar(&storePath, "store", "", "the market data store directory to read the candles from instead of the data directory")
+	flag.IntVar(&interval, "interval", 1440, "the duration in minutes of the candles read from the market data store")
+	flag.StringVar(&from, "from", "", "the first day of the backtest (YYYY-MM-DD), the start of the data by default")
+	flag.StringVar(&to, "to", "", "the last day of the backtest (YYYY-MM-DD), the end of the data by default")
+	flag.Float64Var(&funds, "funds", 1000000, "the quote currency funds of the simulated account")
+	flag.BoolVar(&verbose, "verbose", false, "log the replayed rounds")
+}
+
+func main() {
+	flag.Parse()
+
+	err := run(os.Stdout)
+	if err != nil {
+		log.Fatalf("The backtest failed : %v", err)
+	}
+}
+
+func run(output io.Writer) error {
+	config, err := domain.ParseConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("can't load the configuration : %w", err)
+	}
+
+	options := backtest.Options{Funds: funds}
+	options.From, err = parseDay(from)
+	if err != nil {
+		return fmt.Errorf("the from date is invalid : %w", err)
+	}
+	options.To, err = parseDay(to)
+	if err != nil {
+		return fmt.Errorf("the to date is invalid : %w", err)
+	}
+
+	candles, err := loadCandles(config.TradedPairs(), options)
+	if err != nil {
+		return err
+	}
+
+	if !verbose {
+		log.SetOutput(ioutil.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	report, err := backtest.Run(*config, candles, options)
+	if err != nil {
+		return err
+	}
+
+	return report.Write(output)
+}
+
+// loadCandles Get the candles of the pairs from the market data store, or from the CSV files of the data directory
+func loadCandles(pairs []string, options backtest.Options) (map[string][]domain.Candle, error) {
+	var store marketdata.Store
+	if storePath != "" {
+		store = marketdata.NewFileStore(storePath)
+	}
+
+	candles := map[string][]domain.Candle{}
+	for _, pair := range pairs {
+		var err error
+		if store != nil {
+			candles[pair], err = store.Candles(pair, interval, time.Time{}, options.To)
+		} else {
+			candles[pair], err = marketdata.LoadCsv(filepath.Join(dataPath, pair+".csv"))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the %s candles cannot be loaded : %w", pair, err)
+		}
+	}
+
+	return candles, nil
+}
+
+// parseDay Parse a YYYY-MM-DD date, an empty one being the zero time
+func parseDay(day string) (time.Time, error) {
+	if day == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse("2006-01-02", day)
+}
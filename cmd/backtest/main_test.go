@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"kraken-dca-bot/internal/marketdata"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	configPath = "../../test/data/backtest/config.yaml"
+	dataPath = "../../test/data/backtest"
+	storePath = ""
+	from = "2022-01-02"
+	to = ""
+	funds = 1000
+
+	var output bytes.Buffer
+	err := run(&output)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	if !strings.Contains(output.String(), "Backtest from 2022-01-02 to 2022-01-03 (2 rounds)") || !strings.Contains(output.String(), "Total invested : 20.00") {
+		t.Errorf("The report is %s", output.String())
+	}
+}
+
+func TestRunMissingDataFail(t *testing.T) {
+	configPath = "../../test/data/bot-test-config.yaml"
+	dataPath = "../../test/data/backtest"
+	storePath = ""
+
+	err := run(&bytes.Buffer{})
+	if err == nil || !strings.HasPrefix(err.Error(), "the XETHZEUR candles cannot be loaded :") {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestRunFromStore(t *testing.T) {
+	candles, err := marketdata.LoadCsv("../../test/data/backtest/XXBTZEUR.csv")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	configPath = "../../test/data/backtest/config.yaml"
+	storePath = t.TempDir()
+	interval = 1440
+	from = ""
+	to = "2022-01-02"
+	funds = 1000
+
+	err = marketdata.NewFileStore(storePath).SaveCandles("XXBTZEUR", interval, candles)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	var output bytes.Buffer
+	err = run(&output)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	if !strings.Contains(output.String(), "Backtest from 2022-01-01 to 2022-01-02 (2 rounds)") {
+		t.Errorf("The report is %s", output.String())
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	_ "kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/marketdata"
+	"kraken-dca-bot/internal/storage"
+	"log"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var newExchange = exchange.New
+var newHistory = storage.NewFileHistory
+
+var configPath string
+var storePath string
+var interval int
+
+func init() {
+	flag.StringVar(&configPath, "config", "config.yaml", "the configuration file path with the transaction history path")
+	flag.StringVar(&storePath, "store", "", "the market data store directory to value the past holdings with, the transaction prices being used otherwise")
+	flag.IntVar(&interval, "interval", 1440, "the duration in minutes of the candles read from the market data store")
+}
+
+func main() {
+	flag.Parse()
+
+	err := run(os.Stdout)
+	if err != nil {
+		log.Fatalf("The benchmark failed : %v", err)
+	}
+}
+
+func run(output io.Writer) error {
+	config, err := domain.ParseConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("can't load the configuration : %w", err)
+	}
+
+	transactions, err := newHistory(config.Storage).Transactions()
+	if err != nil {
+		return fmt.Errorf("can't read the transaction history : %w", err)
+	}
+
+	market, err := newExchange(*config)
+	if err != nil {
+		return fmt.Errorf("can't connect to the exchange : %w", err)
+	}
+
+	prices := map[string]float64{}
+	candles := map[string][]domain.Candle{}
+	for _, transaction := range transactions {
+		if _, ok := prices[transaction.Pair]; ok {
+			continue
+		}
+
+		ticker, err := market.Ticker(transaction.Pair)
+		if err != nil {
+			return fmt.Errorf("the %s price cannot be collected : %w", transaction.Pair, err)
+		}
+		prices[transaction.Pair] = ticker.Bid
+
+		if storePath != "" {
+			candles[transaction.Pair], err = marketdata.NewFileStore(storePath).Candles(transaction.Pair, interval, time.Time{}, time.Time{})
+			if err != nil {
+				return fmt.Errorf("the %s candles cannot be loaded : %w", transaction.Pair, err)
+			}
+		}
+	}
+
+	benchmarks := domain.NewBenchmarks(transactions, time.Now(), prices, candlesHistory(candles))
+
+	return write(output, benchmarks)
+}
+
+// candlesHistory Get the price history of the candles, a pair price at a date being the open price of the latest
+// candle started
+func candlesHistory(candles map[string][]domain.Candle) domain.PriceHistory {
+	return func(pair string, date time.Time) (float64, bool) {
+		price, found := 0.0, false
+		for _, candle := range candles[pair] {
+			if candle.Time.After(date) {
+				break
+			}
+			price, found = candle.Open, true
+		}
+
+		return price, found
+	}
+}
+
+// write Print a line per benchmark, the overall one being last
+func write(output io.Writer, benchmarks []domain.Benchmark) error {
+	if len(benchmarks) == 0 {
+		_, err := fmt.Fprintln(output, "No transaction to benchmark")
+
+		return err
+	}
+
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "Pair\tSince\tInvested\tDCA value\tLump-sum value\tAdvantage\tXIRR\tTWR\t")
+	for _, benchmark := range benchmarks {
+		pair := benchmark.Pair
+		if pair == "" {
+			pair = "Total"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%.2f\t%.2f\t%.2f\t%+.2f\t%s\t%s\t\n", pair, benchmark.First.Format("2006-01-02"), benchmark.Invested,
+			benchmark.Value+benchmark.Proceeds, benchmark.LumpSum, benchmark.Advantage(), percentage(benchmark.XIRR), percentage(benchmark.TWR))
+	}
+
+	return table.Flush()
+}
+
+func percentage(rate float64) string {
+	if math.IsNaN(rate) {
+		return "n/a"
+	}
+
+	return fmt.Sprintf("%.2f%%", rate*100)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/marketdata"
+	"kraken-dca-bot/internal/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Pairs["XXBTZEUR"] = &fake.Pair{Base: "XXBT", Quote: "ZEUR", Ask: 30000, Bid: 30000}
+
+	newExchange = func(domain.Config) (exchange.Exchange, error) {
+		return kraken.NewExchange(kraken.NewApiWithClient("", "", server.Client())), nil
+	}
+	defer func() {
+		newExchange = exchange.New
+	}()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := storage.NewMemoryHistory()
+	for index, price := range []float64{20000, 10000} {
+		transaction := domain.NewTransaction("XXBTZEUR").Complete("TX", price, 0.01, 0)
+		transaction.Date = start.AddDate(0, index, 0)
+		err := history.Record(transaction)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred : %v", err)
+		}
+	}
+	newHistory = func(string) storage.History {
+		return history
+	}
+
+	configPath = "../../test/data/backtest/config.yaml"
+	storePath = t.TempDir()
+	interval = 1440
+	err := marketdata.NewFileStore(storePath).SaveCandles("XXBTZEUR", interval, []domain.Candle{{Time: start, Open: 20000, Close: 20000}})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	var output bytes.Buffer
+	err = run(&output)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	lines := strings.Split(output.String(), "\n")
+	if len(lines) != 4 || strings.Join(strings.Fields(lines[1])[:6], " ") != "XXBTZEUR 2022-01-01 300.00 600.00 450.00 +150.00" || strings.Fields(lines[2])[0] != "Total" {
+		t.Errorf("The benchmark is %s", output.String())
+	}
+}
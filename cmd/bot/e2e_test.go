@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/mocks"
+	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/shadow"
+	"kraken-dca-bot/internal/storage"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cancelingInvestor cancels the bot context once its first round is over
+type cancelingInvestor struct {
+	kraken.Investor
+	cancel context.CancelFunc
+}
+
+func (c cancelingInvestor) Invest() []*domain.Transaction {
+	defer c.cancel()
+
+	return c.Investor.Invest()
+}
+
+// setupEndToEnd Run the bot with its real services against a fake Kraken server, only the notifier being mocked.
+// The bot stops after its first round.
+func setupEndToEnd(t *testing.T, cancel context.CancelFunc) (*fake.Server, storage.History, func()) {
+	controller := gomock.NewController(t)
+
+	staging = false
+	configPath = "../../test/data/bot-e2e-config.yaml"
+
+	server := fake.NewServer()
+	server.Balances = map[string]float64{"ZEUR": 1000}
+	server.Pairs = map[string]*fake.Pair{
+		"XETHZEUR": {Base: "XETH", Quote: "ZEUR", Ask: 1000, Bid: 999, Fee: 0.26, LotDecimals: 8},
+		"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR", Ask: 20000, Bid: 19990, Fee: 0.26, LotDecimals: 8},
+	}
+
+	directory := t.TempDir()
+	history := storage.NewFileHistory(filepath.Join(directory, "history.json"))
+
+	newExchange = func(config domain.Config) (exchange.Exchange, error) {
+		return kraken.NewExchange(kraken.NewApiWithClient(config.Kraken.Key, config.Kraken.Secret, server.Client())), nil
+	}
+	newTradingService = kraken.NewTrader
+	newAccountService = kraken.NewAccount
+	newHistory = func(path string) storage.History {
+		return history
+	}
+	newState = func(path string) storage.State {
+		return storage.NewFileState(filepath.Join(directory, "state.json"))
+	}
+	newDepositWatcher = kraken.NewDepositWatcher
+	newWithdrawer = kraken.NewWithdrawer
+	newShadowRunner = shadow.NewRunner
+	newStatusChecker = kraken.NewStatusChecker
+	newClock = kraken.NewClock
+
+	notifier = mocks.NewMockNotifier(controller)
+	newNotifier = func(config *domain.Config) notify.Notifier {
+		return notifier
+	}
+
+	newInvestingService = func(config domain.Config, accountService kraken.Account, tradingService kraken.Trader, notifier notify.Notifier, history storage.History, state storage.State, now func() time.Time) kraken.Investor {
+		return cancelingInvestor{
+			Investor: kraken.NewInvestingServiceWithClock(config, accountService, tradingService, notifier, history, state, now),
+			cancel:   cancel,
+		}
+	}
+
+	return server, history, func() {
+		server.Close()
+		newExchange = exchange.New
+		controller.Finish()
+	}
+}
+
+func TestEndToEndRound(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server, history, cleanUp := setupEndToEnd(t, cancel)
+	defer cleanUp()
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	transactions, err := history.Transactions()
+	if err != nil || len(transactions) != 2 {
+		t.Fatalf("The history is %v (%v)", transactions, err)
+	}
+
+	if len(server.Orders) != 2 || server.Balances["ZEUR"] < 849 || server.Balances["ZEUR"] > 851 {
+		t.Errorf("The orders are %v and the balances %v", server.Orders, server.Balances)
+	}
+
+	// The order volumes are rounded to 6 decimals
+	if math.Abs(server.Balances["XETH"]-transactions[0].Amount) > 1e-6 || math.Abs(server.Balances["XXBT"]-transactions[1].Amount) > 1e-6 {
+		t.Errorf("The balances %v don't match the transactions %v", server.Balances, transactions)
+	}
+}
+
+func TestEndToEndMaintenance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server, history, cleanUp := setupEndToEnd(t, cancel)
+	defer cleanUp()
+
+	server.Status = fake.Maintenance
+	// The round is deferred once, then run when the maintenance is over
+	notifier.EXPECT().NotifyDeferral(gomock.Any()).DoAndReturn(func(deferral *domain.Deferral) error {
+		if deferral.System != fake.Maintenance || len(deferral.Pairs) != 0 {
+			t.Errorf("The deferral is %v", deferral)
+		}
+
+		server.Lock()
+		server.Status = fake.Online
+		server.Unlock()
+
+		return nil
+	})
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	transactions, err := history.Transactions()
+	if err != nil || len(transactions) != 2 {
+		t.Errorf("The deferred round should be run : %v (%v)", transactions, err)
+	}
+}
+
+func TestEndToEndCancelOnlyPair(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server, history, cleanUp := setupEndToEnd(t, cancel)
+	defer cleanUp()
+
+	server.Pairs["XETHZEUR"].Status = fake.CancelOnly
+	notifier.EXPECT().NotifyDeferral(gomock.Any()).DoAndReturn(func(deferral *domain.Deferral) error {
+		if deferral.System != "" || deferral.Pairs["XETHZEUR"] != fake.CancelOnly || len(deferral.Pairs) != 1 {
+			t.Errorf("The deferral is %v", deferral)
+		}
+
+		server.Lock()
+		server.Pairs["XETHZEUR"].Status = fake.Online
+		server.Unlock()
+
+		return nil
+	})
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	transactions, err := history.Transactions()
+	if err != nil || len(transactions) != 2 || len(server.Orders) != 2 {
+		t.Errorf("The deferred round should be run : %v (%v)", transactions, err)
+	}
+}
+
+func TestEndToEndClockDrift(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server, history, cleanUp := setupEndToEnd(t, cancel)
+	defer cleanUp()
+
+	server.Now = func() time.Time {
+		return time.Now().Add(5 * time.Minute)
+	}
+	notifier.EXPECT().NotifyDeferral(gomock.Any()).DoAndReturn(func(deferral *domain.Deferral) error {
+		if deferral.Drift < 299*time.Second || deferral.Drift > 301*time.Second {
+			t.Errorf("The deferral is %v", deferral)
+		}
+
+		server.Lock()
+		server.Now = time.Now
+		server.Unlock()
+
+		return nil
+	})
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	transactions, err := history.Transactions()
+	if err != nil || len(transactions) != 2 {
+		t.Errorf("The deferred round should be run : %v (%v)", transactions, err)
+	}
+}
+
+func TestEndToEndInsufficientFunds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server, _, cleanUp := setupEndToEnd(t, cancel)
+	defer cleanUp()
+
+	server.Fail("AddOrder", "EOrder:Insufficient funds")
+	notifier.EXPECT().NotifyFailure(gomock.Any()).Return(nil).Times(4)
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	if len(server.Orders) != 0 || server.Balances["ZEUR"] != 1000 {
+		t.Errorf("No order should be filled : %v", server.Orders)
+	}
+}
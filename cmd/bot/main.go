@@ -4,21 +4,39 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	krakenapi "github.com/beldur/kraken-go-api-client"
-	"github.com/xhit/go-str2duration/v2"
+	"io"
 	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
 	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/marketdata"
 	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/paper"
+	"kraken-dca-bot/internal/shadow"
+	"kraken-dca-bot/internal/storage"
+	"kraken-dca-bot/internal/webhook"
 	"log"
+	"net/http"
 	"os"
 	"time"
 )
 
-var newApi = krakenapi.New
+var newExchange = exchange.New
+var newPaperExchange = paper.NewExchange
 var newTradingService = kraken.NewTrader
+var newCandlesTradingService = kraken.NewTraderWithCandles
+var newCandleSource = func(market exchange.Exchange, path string) (kraken.CandleSource, error) {
+	return marketdata.NewStoreCandles(market, marketdata.NewFileStore(path))
+}
 var newAccountService = kraken.NewAccount
 var newNotifier = notify.NewEmailNotifier
-var newInvestingService = kraken.NewInvestingService
+var newInvestingService = kraken.NewInvestingServiceWithClock
+var newHistory = storage.NewFileHistory
+var newState = storage.NewFileState
+var newDepositWatcher = kraken.NewDepositWatcher
+var newWithdrawer = kraken.NewWithdrawer
+var newShadowRunner = shadow.NewRunner
+var newStatusChecker = kraken.NewStatusChecker
+var newClock = kraken.NewClock
 
 var staging bool
 var configPath string
@@ -53,36 +71,274 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("can't load the configuration : %w", err)
 	}
 
-	api := newApi(config.Kraken.Key, config.Kraken.Secret)
-	tradingService := newTradingService(api, staging)
-	accountService := newAccountService(api)
+	account, err := newExchange(*config)
+	if err != nil {
+		return fmt.Errorf("can't connect to the exchange : %w", err)
+	}
+	if closer, ok := account.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	// The clock is checked against the exchange time, paper trading included
+	clock, err := newClock(account, config.Clock)
+	if err != nil {
+		return fmt.Errorf("cannot parse the clock drifts : %w", err)
+	}
+
+	// The market history is downloaded from the exchange, paper trading included
+	var candles kraken.CandleSource
+	if config.MarketData != "" {
+		candles, err = newCandleSource(account, config.MarketData)
+		if err != nil {
+			return fmt.Errorf("cannot read the candles from the market data store : %w", err)
+		}
+	}
+
+	if config.Paper != nil {
+		account = newPaperExchange(account, *config.Paper, newState(config.Paper.Portfolio))
+	}
+
+	var tradingService kraken.Trader
+	if candles != nil {
+		tradingService = newCandlesTradingService(account, staging, candles)
+	} else {
+		tradingService = newTradingService(account, staging)
+	}
+	accountService := newAccountService(account)
 	notifier := newNotifier(config)
-	investingService := newInvestingService(*config, accountService, tradingService, notifier)
+	history := newHistory(config.Storage)
+	state := newState(config.State)
+	investingService := newInvestingService(*config, accountService, tradingService, notifier, history, state, clock.Now)
+
+	var scheduled <-chan time.Time
+	if config.Scheduled() {
+		frequency, err := config.Period()
+		if err != nil {
+			return fmt.Errorf("cannot parse the DCA frequency environment variable : %w", err)
+		}
+
+		ticker := time.NewTicker(frequency)
+		defer ticker.Stop()
+		scheduled = ticker.C
+	}
+
+	var deposits <-chan time.Time
+	depositWatcher := newDepositWatcher(config.Currency, accountService, state, clock.Now)
+	if config.Deposits != nil {
+		interval, err := config.Deposits.PollInterval()
+		if err != nil {
+			return fmt.Errorf("cannot parse the deposits poll interval : %w", err)
+		}
+
+		depositTicker := time.NewTicker(interval)
+		defer depositTicker.Stop()
+		deposits = depositTicker.C
+	}
+
+	var webhookRequests chan webhook.Request
+	if config.Webhook != nil {
+		webhookRequests = make(chan webhook.Request, 16)
+		server := &http.Server{
+			Addr:    config.Webhook.Address,
+			Handler: webhook.NewHandler(*config.Webhook, webhookRequests),
+		}
+		go func() {
+			err := server.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				log.Printf("The webhook listener stopped : %v", err)
+			}
+		}()
+		defer server.Close()
+		log.Printf("Listening to webhook requests on %s", config.Webhook.Address)
+	}
 
-	frequency, err := str2duration.ParseDuration(config.Frequency)
+	var withdrawer kraken.Withdrawer
+	if config.Withdrawals != nil {
+		// The staging runs never move funds
+		withdrawals := *config.Withdrawals
+		withdrawals.DryRun = withdrawals.DryRun || staging
+		withdrawer = newWithdrawer(withdrawals, accountService, notifier, state, clock.Now)
+	}
+
+	var shadowRunner shadow.Runner
+	if config.Shadows != nil {
+		shadowRunner = newShadowRunner(*config, account, history, notifier, state, staging, clock.Now)
+	}
+
+	delay, maxDelay, err := config.Recheck.Delays()
+	if err != nil {
+		return fmt.Errorf("cannot parse the recheck delays : %w", err)
+	}
+	clockInterval, err := config.Clock.CheckInterval()
 	if err != nil {
-		return fmt.Errorf("cannot parse the DCA frequency environment variable : %w", err)
+		return fmt.Errorf("cannot parse the clock check interval : %w", err)
 	}
+	clockTicker := time.NewTicker(clockInterval)
+	defer clockTicker.Stop()
 
-	ticker := time.NewTicker(frequency)
-	defer ticker.Stop()
+	rounds := &deferrer{
+		statusChecker: newStatusChecker(account, config.TradedPairs(), clock),
+		delay:         delay,
+		maxDelay:      maxDelay,
+	}
 
-	tick(investingService, notifier)
+	if scheduled != nil {
+		rounds.schedule()
+	}
+	rounds.run(investingService, withdrawer, shadowRunner, notifier, config.Summary)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
-			tick(investingService, notifier)
+		case <-scheduled:
+			rounds.schedule()
+		case <-rounds.recheck:
+			rounds.recheck = nil
+		case <-clockTicker.C:
+			checkClock(clock)
+
+			continue
+		case <-deposits:
+			// The deposits are left to the next poll while trading is restricted
+			if rounds.deferral != nil {
+				continue
+			}
+			watchDeposits(depositWatcher, rounds, config)
+		case request := <-webhookRequests:
+			rounds.queue(map[string]float64{request.Pair: request.Amount})
 		}
+
+		rounds.run(investingService, withdrawer, shadowRunner, notifier, config.Summary)
 	}
 }
 
-func tick(investingService kraken.Investor, notifier notify.Notifier) {
-	transactions := investingService.Invest()
+// tick Run an investment round, then the shadow strategies when there are some
+func tick(investingService kraken.Investor, shadowRunner shadow.Runner, notifier notify.Notifier, summary bool) {
+	handle(investingService.Invest(), notifier, summary)
 
+	if shadowRunner != nil {
+		shadowRunner.Run()
+	}
+}
+
+// deferrer postpones the rounds while trading is restricted, re-checking the trading status with a delay doubling
+// from `delay` up to `maxDelay`. The rounds due in the meantime are run once trading is available : the scheduled
+// ones are merged into a single round, the deposit and webhook ones are run in order.
+type deferrer struct {
+	statusChecker kraken.StatusChecker
+	delay         time.Duration
+	maxDelay      time.Duration
+
+	// scheduled is whether a scheduled round is due
+	scheduled bool
+	// amounts are the amounts of the due deposit and webhook rounds
+	amounts []map[string]float64
+	// deferral is the pending deferral, nil when trading was available at the last check
+	deferral *domain.Deferral
+	// recheck is the channel of the next status check while deferred, nil otherwise
+	recheck <-chan time.Time
+	next    time.Duration
+}
+
+// schedule Make a scheduled round due
+func (d *deferrer) schedule() {
+	d.scheduled = true
+}
+
+// queue Make a round investing fixed amounts in pairs due
+func (d *deferrer) queue(amounts map[string]float64) {
+	d.amounts = append(d.amounts, amounts)
+}
+
+// run Run the due rounds when trading is available, otherwise defer them until the re-check. Only the first check of
+// a deferral is notified, instead of every pair failing at every re-check. Nothing is checked while waiting for a
+// re-check.
+func (d *deferrer) run(investingService kraken.Investor, withdrawer kraken.Withdrawer, shadowRunner shadow.Runner, notifier notify.Notifier, summary bool) {
+	if d.recheck != nil || (!d.scheduled && len(d.amounts) == 0) {
+		return
+	}
+
+	deferral := d.statusChecker.Check()
+	if deferral == nil {
+		if d.deferral != nil {
+			log.Printf("Trading is available again, running the rounds deferred since %s", d.deferral.Date.Format(time.RFC3339))
+		}
+		d.deferral = nil
+
+		if d.scheduled {
+			d.scheduled = false
+			tick(investingService, shadowRunner, notifier, summary)
+		}
+
+		amounts := d.amounts
+		d.amounts = nil
+		for _, pairAmounts := range amounts {
+			handle(investingService.InvestAmounts(pairAmounts), notifier, summary)
+		}
+
+		// The holdings are swept to cold storage once the due rounds are run, whatever triggered them
+		if withdrawer != nil {
+			withdrawer.Sweep()
+		}
+
+		return
+	}
+
+	if d.deferral == nil {
+		d.next = d.delay
+	}
+	deferral.Retry = deferral.Date.Add(d.next)
+	log.Println(deferral)
+
+	if d.deferral == nil {
+		err := notifier.NotifyDeferral(deferral)
+		if err != nil {
+			log.Printf("An error as occurred during the deferral notification : %v", err)
+		}
+		d.deferral = deferral
+	}
+
+	d.recheck = time.After(d.next)
+	d.next *= 2
+	if d.next > d.maxDelay {
+		d.next = d.maxDelay
+	}
+}
+
+// checkClock Measure the drift of the local clock, the rounds being deferred by their status check while it's too high
+func checkClock(clock kraken.Clock) {
+	drift, err := clock.Sync()
+	if err != nil {
+		log.Printf("The clock check failed : %v", err)
+		return
+	}
+
+	log.Printf("The local clock drifts by %s from the exchange time", drift)
+}
+
+// watchDeposits Queue the investment of the deposits received since the last poll, split according to the deposits
+// allocation
+func watchDeposits(depositWatcher kraken.DepositWatcher, rounds *deferrer, config *domain.Config) {
+	deposits, err := depositWatcher.Poll()
+	if err != nil {
+		log.Printf("An error occurred while polling the deposits : %v", err)
+		return
+	}
+
+	for _, deposit := range deposits {
+		log.Printf("Investing the %.2f %s deposit %s", deposit.Net(), deposit.Asset, deposit.Id)
+		rounds.queue(config.Deposits.Split(deposit.Net()))
+	}
+}
+
+// handle Notify the failed transactions of an investment round, and its summary when enabled
+func handle(transactions []*domain.Transaction, notifier notify.Notifier, summary bool) {
 	for _, transaction := range transactions {
+		if staging {
+			log.Printf("Staged transaction : %v", transaction)
+		}
+
 		if transaction.Exception != nil {
 			err := notifier.NotifyFailure(transaction)
 			if err != nil {
@@ -90,4 +346,11 @@ func tick(investingService kraken.Investor, notifier notify.Notifier) {
 			}
 		}
 	}
+
+	if summary {
+		err := notifier.NotifySummary(transactions)
+		if err != nil {
+			log.Printf("An error as occurred during the summary notification : %v", err)
+		}
+	}
 }
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"github.com/golang/mock/gomock"
 	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
 	"kraken-dca-bot/internal/kraken"
 	"kraken-dca-bot/internal/mocks"
 	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/shadow"
+	"kraken-dca-bot/internal/storage"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +20,11 @@ var tradingService *mocks.MockTrader
 var accountService *mocks.MockAccount
 var notifier *mocks.MockNotifier
 var investingService *mocks.MockInvestor
+var depositWatcher *mocks.MockDepositWatcher
+var withdrawer *mocks.MockWithdrawer
+var shadowRunner *mocks.MockRunner
+var statusChecker *mocks.MockStatusChecker
+var clock *mocks.MockClock
 
 func setup(t *testing.T) func() {
 	controller := gomock.NewController(t)
@@ -25,12 +33,15 @@ func setup(t *testing.T) func() {
 	configPath = "../../test/data/bot-test-config.yaml"
 
 	tradingService = mocks.NewMockTrader(controller)
-	newTradingService = func(api kraken.ApiInterface, staging bool) kraken.Trader {
+	newTradingService = func(account exchange.Exchange, staging bool) kraken.Trader {
+		return tradingService
+	}
+	newCandlesTradingService = func(account exchange.Exchange, staging bool, candles kraken.CandleSource) kraken.Trader {
 		return tradingService
 	}
 
 	accountService = mocks.NewMockAccount(controller)
-	newAccountService = func(api kraken.ApiInterface) kraken.Account {
+	newAccountService = func(account exchange.Exchange) kraken.Account {
 		return accountService
 	}
 
@@ -40,7 +51,41 @@ func setup(t *testing.T) func() {
 	}
 
 	investingService = mocks.NewMockInvestor(controller)
-	newInvestingService = func(config domain.Config, accountService kraken.Account, tradingService kraken.Trader, notifier notify.Notifier) kraken.Investor {
+	newHistory = func(path string) storage.History {
+		return mocks.NewMockHistory(controller)
+	}
+
+	newState = func(path string) storage.State {
+		return mocks.NewMockState(controller)
+	}
+
+	depositWatcher = mocks.NewMockDepositWatcher(controller)
+	newDepositWatcher = func(currency string, accountService kraken.Account, state storage.State, now func() time.Time) kraken.DepositWatcher {
+		return depositWatcher
+	}
+
+	withdrawer = mocks.NewMockWithdrawer(controller)
+	newWithdrawer = func(config domain.Withdrawals, accountService kraken.Account, notifier notify.Notifier, state storage.State, now func() time.Time) kraken.Withdrawer {
+		return withdrawer
+	}
+
+	shadowRunner = mocks.NewMockRunner(controller)
+	newShadowRunner = func(config domain.Config, market exchange.Exchange, history storage.History, notifier notify.Notifier, state storage.State, staging bool, now func() time.Time) shadow.Runner {
+		return shadowRunner
+	}
+
+	statusChecker = mocks.NewMockStatusChecker(controller)
+	statusChecker.EXPECT().Check().Return(nil).AnyTimes()
+	newStatusChecker = func(exchange exchange.Exchange, pairs []string, clock kraken.Clock) kraken.StatusChecker {
+		return statusChecker
+	}
+
+	clock = mocks.NewMockClock(controller)
+	newClock = func(exchange exchange.Exchange, config domain.Clock) (kraken.Clock, error) {
+		return clock, nil
+	}
+
+	newInvestingService = func(config domain.Config, accountService kraken.Account, tradingService kraken.Trader, notifier notify.Notifier, history storage.History, state storage.State, now func() time.Time) kraken.Investor {
 		return investingService
 	}
 
@@ -166,3 +211,268 @@ func TestBotFrequencyParseFail(t *testing.T) {
 		t.Errorf("An unexpected error was raised : %v", err)
 	}
 }
+
+func TestBotSummary(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-summary-config.yaml"
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	transactions := []*domain.Transaction{
+		{
+			Id:        "TXID1",
+			Date:      time.Time{},
+			Exception: nil,
+		},
+	}
+
+	investingService.EXPECT().Invest().DoAndReturn(func() []*domain.Transaction {
+		cancel()
+		return transactions
+	})
+	notifier.EXPECT().NotifySummary(transactions).Return(errors.New("summary error"))
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotDeposits(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-deposits-config.yaml"
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	investingService.EXPECT().Invest().Return([]*domain.Transaction{})
+	depositWatcher.EXPECT().Poll().Return([]domain.Ledger{{Id: "L1", Asset: "ZEUR", Amount: 500, Fee: 0}}, nil)
+	investingService.EXPECT().InvestAmounts(map[string]float64{"XETHZEUR": 200, "XXBTZEUR": 300}).DoAndReturn(func(amounts map[string]float64) []*domain.Transaction {
+		cancel()
+		return []*domain.Transaction{}
+	})
+	depositWatcher.EXPECT().Poll().Return(nil, nil).AnyTimes()
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotUnscheduledDeposits(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-unscheduled-deposits-config.yaml"
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Only the deposits trigger the rounds
+	investingService.EXPECT().Invest().Times(0)
+	depositWatcher.EXPECT().Poll().Return([]domain.Ledger{{Id: "L1", Asset: "ZEUR", Amount: 500, Fee: 0}}, nil)
+	investingService.EXPECT().InvestAmounts(map[string]float64{"XETHZEUR": 200, "XXBTZEUR": 300}).DoAndReturn(func(amounts map[string]float64) []*domain.Transaction {
+		cancel()
+		return []*domain.Transaction{}
+	})
+	depositWatcher.EXPECT().Poll().Return(nil, nil).AnyTimes()
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotUnscheduledWithdrawals(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-unscheduled-withdrawals-config.yaml"
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	// The holdings are swept after the deposit rounds too
+	gomock.InOrder(
+		depositWatcher.EXPECT().Poll().Return([]domain.Ledger{{Id: "L1", Asset: "ZEUR", Amount: 500, Fee: 0}}, nil),
+		investingService.EXPECT().InvestAmounts(map[string]float64{"XETHZEUR": 200, "XXBTZEUR": 300}).Return([]*domain.Transaction{}),
+		withdrawer.EXPECT().Sweep().DoAndReturn(func() []*domain.Withdrawal {
+			cancel()
+			return nil
+		}),
+	)
+	depositWatcher.EXPECT().Poll().Return(nil, nil).AnyTimes()
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotDeferredDeposits(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-deposits-config.yaml"
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	restrictedChecker := mocks.NewMockStatusChecker(controller)
+	newStatusChecker = func(exchange exchange.Exchange, pairs []string, clock kraken.Clock) kraken.StatusChecker {
+		return restrictedChecker
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	// The deposit polled right before trading is restricted is invested once it's available again
+	gomock.InOrder(
+		restrictedChecker.EXPECT().Check().Return(nil),
+		investingService.EXPECT().Invest().Return([]*domain.Transaction{}),
+		depositWatcher.EXPECT().Poll().Return([]domain.Ledger{{Id: "L1", Asset: "ZEUR", Amount: 500, Fee: 0}}, nil),
+		restrictedChecker.EXPECT().Check().Return(&domain.Deferral{Date: time.Now(), System: "cancel_only"}),
+		notifier.EXPECT().NotifyDeferral(gomock.Any()).Return(nil),
+		restrictedChecker.EXPECT().Check().Return(nil),
+		investingService.EXPECT().InvestAmounts(map[string]float64{"XETHZEUR": 200, "XXBTZEUR": 300}).DoAndReturn(func(amounts map[string]float64) []*domain.Transaction {
+			cancel()
+			return []*domain.Transaction{}
+		}),
+	)
+	depositWatcher.EXPECT().Poll().Return(nil, nil).AnyTimes()
+	restrictedChecker.EXPECT().Check().Return(nil).AnyTimes()
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotWithdrawals(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-withdrawals-config.yaml"
+	newWithdrawer = func(config domain.Withdrawals, accountService kraken.Account, notifier notify.Notifier, state storage.State, now func() time.Time) kraken.Withdrawer {
+		if !config.DryRun {
+			t.Error("The withdrawals of a staging run should be dry-run")
+		}
+
+		return withdrawer
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	gomock.InOrder(
+		investingService.EXPECT().Invest().Return([]*domain.Transaction{}),
+		withdrawer.EXPECT().Sweep().DoAndReturn(func() []*domain.Withdrawal {
+			cancel()
+			return nil
+		}),
+	)
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+// storedCandles is a candle source standing for the market data store
+type storedCandles struct{}
+
+func (s storedCandles) Candles(pair string, interval int) ([]domain.Candle, error) {
+	return nil, nil
+}
+
+func TestBotMarketData(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-market-data-config.yaml"
+	newCandleSource = func(market exchange.Exchange, path string) (kraken.CandleSource, error) {
+		if path != "market-data" {
+			t.Errorf("The market data store is %s", path)
+		}
+
+		return storedCandles{}, nil
+	}
+	newCandlesTradingService = func(account exchange.Exchange, staging bool, candles kraken.CandleSource) kraken.Trader {
+		if _, ok := candles.(storedCandles); !ok {
+			t.Errorf("The trader reads the candles from %v instead of the store", candles)
+		}
+
+		return tradingService
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	investingService.EXPECT().Invest().DoAndReturn(func() []*domain.Transaction {
+		cancel()
+		return nil
+	})
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotShadows(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	configPath = "../../test/data/bot-shadows-config.yaml"
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	gomock.InOrder(
+		investingService.EXPECT().Invest().Return([]*domain.Transaction{}),
+		shadowRunner.EXPECT().Run().Do(cancel),
+	)
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestBotDeferral(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	// The schedule is too slow to run another round, the deferred one being re-checked through the recheck delay only
+	configPath = "../../test/data/bot-deferral-config.yaml"
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	restrictedChecker := mocks.NewMockStatusChecker(controller)
+	newStatusChecker = func(exchange exchange.Exchange, pairs []string, clock kraken.Clock) kraken.StatusChecker {
+		return restrictedChecker
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	deferral := &domain.Deferral{Date: time.Now(), Pairs: map[string]string{"XETHZEUR": "cancel_only"}}
+	gomock.InOrder(
+		restrictedChecker.EXPECT().Check().Return(deferral),
+		notifier.EXPECT().NotifyDeferral(deferral).Return(nil),
+		restrictedChecker.EXPECT().Check().Return(&domain.Deferral{Date: time.Now(), System: "cancel_only"}).Times(2),
+		restrictedChecker.EXPECT().Check().Return(nil),
+		investingService.EXPECT().Invest().DoAndReturn(func() []*domain.Transaction {
+			cancel()
+			return []*domain.Transaction{}
+		}),
+	)
+
+	err := run(ctx)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
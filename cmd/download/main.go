@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	_ "kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/marketdata"
+	"log"
+	"time"
+)
+
+// pause is the delay between two trades requests, Kraken allowing about one public request per second
+var pause = time.Second
+
+var newExchange = exchange.New
+var newStore = marketdata.NewFileStore
+
+var configPath string
+var storePath string
+var interval int
+var trades bool
+var since string
+
+func init() {
+	flag.StringVar(&configPath, "config", "config.yaml", "the configuration file path with the traded pairs")
+	flag.StringVar(&storePath, "store", "market-data", "the market data store directory")
+	flag.IntVar(&interval, "interval", 1440, "the duration in minutes of the downloaded candles")
+	flag.BoolVar(&trades, "trades", false, "download the trade history too")
+	flag.StringVar(&since, "since", "", "the first day of the trade history (YYYY-MM-DD) when none is stored, its start by default")
+}
+
+func main() {
+	flag.Parse()
+
+	err := run()
+	if err != nil {
+		log.Fatalf("The download failed : %v", err)
+	}
+}
+
+func run() error {
+	config, err := domain.ParseConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("can't load the configuration : %w", err)
+	}
+
+	var start time.Time
+	if since != "" {
+		start, err = time.Parse("2006-01-02", since)
+		if err != nil {
+			return fmt.Errorf("the since date is invalid : %w", err)
+		}
+	}
+
+	market, err := newExchange(*config)
+	if err != nil {
+		return fmt.Errorf("can't connect to the exchange : %w", err)
+	}
+
+	history, ok := market.(exchange.MarketHistory)
+	if !ok {
+		return fmt.Errorf("the %s market history cannot be downloaded : %w", config.Exchange, exchange.ErrUnsupported)
+	}
+
+	downloader := marketdata.NewDownloader(history, newStore(storePath), pause)
+	for _, pair := range config.TradedPairs() {
+		_, err = downloader.DownloadCandles(pair, interval)
+		if err != nil {
+			return err
+		}
+
+		if trades {
+			_, err = downloader.DownloadTrades(pair, start)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/marketdata"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	day := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.Pairs["XXBTZEUR"] = &fake.Pair{
+		Base:    "XXBT",
+		Quote:   "ZEUR",
+		Candles: []domain.Candle{{Time: day, Open: 40000, High: 41000, Low: 39000, Close: 40500, Volume: 10}},
+		Trades: []domain.Trade{
+			{Time: day.Add(-time.Hour), Price: 39000, Volume: 0.2, Side: domain.Sell},
+			{Time: day.Add(time.Hour), Price: 40100, Volume: 0.1, Side: domain.Buy},
+		},
+	}
+
+	newExchange = func(domain.Config) (exchange.Exchange, error) {
+		return kraken.NewExchange(kraken.NewApiWithClient("", "", server.Client())), nil
+	}
+	defer func() {
+		newExchange = exchange.New
+	}()
+
+	configPath = "../../test/data/backtest/config.yaml"
+	storePath = t.TempDir()
+	interval = 1440
+	trades = true
+	since = "2022-01-01"
+	pause = 0
+
+	err := run()
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	store := marketdata.NewFileStore(storePath)
+	candles, err := store.Candles("XXBTZEUR", 1440, time.Time{}, time.Time{})
+	if err != nil || len(candles) != 1 || candles[0].Close != 40500 {
+		t.Errorf("The stored candles are %v (%v)", candles, err)
+	}
+
+	stored, err := store.Trades("XXBTZEUR", time.Time{}, time.Time{})
+	if err != nil || len(stored) != 1 || stored[0].Price != 40100 {
+		t.Errorf("The stored trades are %v (%v)", stored, err)
+	}
+}
@@ -5,7 +5,9 @@ go 1.18
 require (
 	github.com/beldur/kraken-go-api-client v0.0.0-20210512194559-2c29669c4ecc
 	github.com/golang/mock v1.6.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/xhit/go-simple-mail/v2 v2.11.0
+	github.com/xhit/go-str2duration/v2 v2.0.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -13,5 +15,4 @@ require (
 	github.com/go-test/deep v1.0.8 // indirect
 	github.com/stretchr/testify v1.8.0 // indirect
 	github.com/toorop/go-dkim v0.0.0-20201103131630-e1cd1a0a5208 // indirect
-	github.com/xhit/go-str2duration/v2 v2.0.0 // indirect
 )
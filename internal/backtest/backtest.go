@@ -0,0 +1,81 @@
+// Package backtest replays the configured strategy over historical candles on a simulated exchange
+package backtest
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/storage"
+	"time"
+)
+
+// Options are the replayed period, the data bounds being used when unset, and the quote currency funds the simulated
+// account starts with
+type Options struct {
+	From  time.Time
+	To    time.Time
+	Funds float64
+}
+
+// Run Replay the investment rounds of the configured frequency between the options bounds, the Investor trading on a
+// market simulated from the candles of every traded pair. The staking, deposits and withdrawals are not simulated.
+func Run(config domain.Config, candles map[string][]domain.Candle, options Options) (Report, error) {
+	period, err := config.Period()
+	if err != nil {
+		return Report{}, fmt.Errorf("cannot parse the DCA frequency : %w", err)
+	}
+
+	pairs := config.TradedPairs()
+	from, to, err := bounds(pairs, candles, options)
+	if err != nil {
+		return Report{}, err
+	}
+
+	config.Pairs = append([]domain.DCAPair{}, config.Pairs...)
+	for index := range config.Pairs {
+		config.Pairs[index].Staking = nil
+	}
+
+	market := NewMarket(candles, config.Currency, options.Funds)
+	account := kraken.NewAccount(market)
+	trader := kraken.NewTrader(market, false)
+	investor := kraken.NewInvestingServiceWithClock(config, account, trader, notify.NewSilentNotifier(), storage.NewMemoryHistory(), storage.NewMemoryState(), market.Now)
+
+	report := newReport(pairs, from, to)
+	for now := from; !now.After(to); now = now.Add(period) {
+		market.SetTime(now)
+		report.add(investor.Invest())
+
+		err = report.value(market)
+		if err != nil {
+			return Report{}, err
+		}
+	}
+
+	return report, nil
+}
+
+// bounds Get the replayed period, restricted to the times all the pairs have candles for
+func bounds(pairs []string, candles map[string][]domain.Candle, options Options) (time.Time, time.Time, error) {
+	from, to := options.From, options.To
+	for _, pair := range pairs {
+		pairCandles := candles[pair]
+		if len(pairCandles) == 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("the %s pair has no historical data", pair)
+		}
+
+		if first := pairCandles[0].Time; from.Before(first) {
+			from = first
+		}
+		if last := pairCandles[len(pairCandles)-1].Time; to.IsZero() || to.After(last) {
+			to = last
+		}
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the historical data has no candles between %s and %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,95 @@
+package backtest
+
+import (
+	"bytes"
+	"kraken-dca-bot/internal/domain"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// dailyCandles Get daily candles opening at the given prices from the start date
+func dailyCandles(opens ...float64) []domain.Candle {
+	candles := make([]domain.Candle, len(opens))
+	for index, open := range opens {
+		candles[index] = domain.Candle{Time: start.AddDate(0, 0, index), Open: open, High: open, Low: open, Close: open, Volume: 1}
+	}
+
+	return candles
+}
+
+var backtestConfig = domain.Config{
+	Frequency: "24h",
+	Currency:  "ZEUR",
+	Pairs:     []domain.DCAPair{{Pair: "XXBTZEUR", Amount: 10}},
+}
+
+func TestRun(t *testing.T) {
+	candles := map[string][]domain.Candle{"XXBTZEUR": dailyCandles(100, 200, 400, 100)}
+
+	report, err := Run(backtestConfig, candles, Options{Funds: 1000})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	pair := report.Pairs[0]
+	if report.Rounds != 4 || pair.Purchases != 4 || pair.Failures != 0 {
+		t.Errorf("The report is %+v", report)
+	}
+
+	if math.Abs(report.Invested()-40) > 1e-9 {
+		t.Errorf("%f was invested instead of 40", report.Invested())
+	}
+
+	bought := (0.1 + 0.05 + 0.025 + 0.1) * (1 - 0.0026)
+	if math.Abs(pair.Bought-bought) > 1e-9 || math.Abs(pair.Holdings-bought) > 1e-9 {
+		t.Errorf("%f was bought and %f is held instead of %f", pair.Bought, pair.Holdings, bought)
+	}
+
+	if math.Abs(pair.AverageCost()-40/bought) > 1e-9 || math.Abs(report.Value()-bought*100) > 1e-9 {
+		t.Errorf("The average cost is %f and the value %f", pair.AverageCost(), report.Value())
+	}
+
+	// The multiple peaks at the third round, the holdings being worth 0.175 * 400 for 30 invested
+	peak := 0.175 * 400 / 30
+	drawdown := (1 - 0.275*100/40/peak) * 100
+	if math.Abs(report.MaxDrawdown-drawdown) > 1e-6 {
+		t.Errorf("The max drawdown is %f instead of %f", report.MaxDrawdown, drawdown)
+	}
+
+	var output bytes.Buffer
+	err = report.Write(&output)
+	if err != nil || !strings.Contains(output.String(), "Total invested : 40.00") || !strings.Contains(output.String(), "XXBTZEUR") {
+		t.Errorf("The written report is %s (%v)", output.String(), err)
+	}
+}
+
+func TestRunInsufficientFunds(t *testing.T) {
+	candles := map[string][]domain.Candle{"XXBTZEUR": dailyCandles(100, 100, 100, 100)}
+
+	report, err := Run(backtestConfig, candles, Options{Funds: 25})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	if report.Pairs[0].Purchases != 2 || report.Pairs[0].Failures != 2 {
+		t.Errorf("The pair report is %+v", report.Pairs[0])
+	}
+}
+
+func TestRunBounds(t *testing.T) {
+	candles := map[string][]domain.Candle{"XXBTZEUR": dailyCandles(100, 100, 100, 100, 100)}
+
+	report, err := Run(backtestConfig, candles, Options{From: start.AddDate(0, 0, 1), To: start.AddDate(0, 0, 2), Funds: 1000})
+	if err != nil || report.Rounds != 2 || !report.From.Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("The report is %+v (%v)", report, err)
+	}
+
+	_, err = Run(backtestConfig, map[string][]domain.Candle{}, Options{Funds: 1000})
+	if err == nil || err.Error() != "the XXBTZEUR pair has no historical data" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package backtest
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCandles is the number of candles returned by Kraken for an OHLC request
+const maxCandles = 720
+
+// feeWindow is the period over which the trading volume sets the fee tier
+const feeWindow = 30 * 24 * time.Hour
+
+// trade is a filled order value, used to compute the fee tier
+type trade struct {
+	time  time.Time
+	value float64
+}
+
+// Market is a simulated exchange replaying historical candles. At the current time, the pairs are traded at the open
+// price of the latest started candle and only the candles finished before are visible, so that the strategy cannot
+// look ahead. The orders are filled immediately and charged the Kraken taker fee of the 30-day volume tier.
+type Market struct {
+	candles  map[string][]domain.Candle
+	pairs    map[string]exchange.Pair
+	now      time.Time
+	balances map[string]float64
+	orders   map[string]exchange.Order
+	trades   []trade
+	mutex    *sync.Mutex
+}
+
+// NewMarket Get a market replaying the candles of each pair, the account holding `funds` of the `currency` quote asset
+func NewMarket(candles map[string][]domain.Candle, currency string, funds float64) *Market {
+	pairs := map[string]exchange.Pair{}
+	for pair := range candles {
+		pairs[pair] = pairRules(pair, currency)
+	}
+
+	return &Market{
+		candles:  candles,
+		pairs:    pairs,
+		balances: map[string]float64{currency: funds},
+		orders:   map[string]exchange.Order{},
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// pairRules Get the rules of a pair quoted in `currency`, its base asset being the rest of its name.
+// Kraken drops the X and Z prefixes of the assets in some pair names (e.g. DOTEUR for DOT and ZEUR).
+func pairRules(pair string, currency string) exchange.Pair {
+	base := strings.TrimSuffix(pair, currency)
+	if base == pair && len(currency) == 4 && strings.ContainsAny(currency[:1], "XZ") {
+		base = strings.TrimSuffix(pair, currency[1:])
+	}
+
+	return exchange.Pair{Base: base, Quote: currency}
+}
+
+// SetTime Move the market to the given time
+func (m *Market) SetTime(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.now = now
+}
+
+// Now Get the market current time
+func (m *Market) Now() time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.now
+}
+
+// Price Get the current price of the pair, i.e. the open price of its latest started candle
+func (m *Market) Price(pair string) (float64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.price(pair)
+}
+
+func (m *Market) price(pair string) (float64, error) {
+	candles, ok := m.candles[pair]
+	if !ok {
+		return 0, fmt.Errorf("the %s pair has no historical data", pair)
+	}
+
+	index := m.started(candles)
+	if index < 0 {
+		return 0, fmt.Errorf("the %s pair has no historical data before %s", pair, m.now.Format(time.RFC3339))
+	}
+
+	return candles[index].Open, nil
+}
+
+// started Get the index of the latest candle started at the current time, -1 if none did
+func (m *Market) started(candles []domain.Candle) int {
+	index := -1
+	for i, candle := range candles {
+		if candle.Time.After(m.now) {
+			break
+		}
+		index = i
+	}
+
+	return index
+}
+
+func (m *Market) Balances() (map[string]float64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	balances := make(map[string]float64, len(m.balances))
+	for asset, quantity := range m.balances {
+		balances[asset] = quantity
+	}
+
+	return balances, nil
+}
+
+func (m *Market) Ticker(pair string) (exchange.Ticker, error) {
+	price, err := m.Price(pair)
+	if err != nil {
+		return exchange.Ticker{}, err
+	}
+
+	return exchange.Ticker{Ask: price, Bid: price}, nil
+}
+
+// Fee Get the taker fee percentage of the current 30-day trading volume
+func (m *Market) Fee(string) (float64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.fee(), nil
+}
+
+func (m *Market) fee() float64 {
+	volume := 0.0
+	for _, trade := range m.trades {
+		if m.now.Sub(trade.time) < feeWindow {
+			volume += trade.value
+		}
+	}
+
+	return TakerFee(volume)
+}
+
+func (m *Market) Pair(pair string) (exchange.Pair, error) {
+	rules, ok := m.pairs[pair]
+	if !ok {
+		return exchange.Pair{}, fmt.Errorf("the %s pair has no historical data", pair)
+	}
+
+	return rules, nil
+}
+
+// Candles Get the candles finished before the current time, merged into `interval` minutes candles when the
+// historical data is more granular
+func (m *Market) Candles(pair string, interval int) ([]domain.Candle, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	candles, ok := m.candles[pair]
+	if !ok {
+		return nil, fmt.Errorf("the %s pair has no historical data", pair)
+	}
+
+	// The latest started candle is still running
+	finished := candles[:m.started(candles)+1]
+	if len(finished) > 0 {
+		finished = finished[:len(finished)-1]
+	}
+
+	resampled := Resample(finished, time.Duration(interval)*time.Minute)
+	if len(resampled) > maxCandles {
+		resampled = resampled[len(resampled)-maxCandles:]
+	}
+
+	return resampled, nil
+}
+
+// Resample Merge the candles into candles of the given duration, the candles already as long being kept as is
+func Resample(candles []domain.Candle, duration time.Duration) []domain.Candle {
+	if len(candles) < 2 || candles[1].Time.Sub(candles[0].Time) >= duration {
+		return append([]domain.Candle{}, candles...)
+	}
+
+	var resampled []domain.Candle
+	for _, candle := range candles {
+		start := candle.Time.Truncate(duration)
+		last := len(resampled) - 1
+		if last < 0 || !resampled[last].Time.Equal(start) {
+			candle.Time = start
+			resampled = append(resampled, candle)
+
+			continue
+		}
+
+		merged := &resampled[last]
+		if candle.High > merged.High {
+			merged.High = candle.High
+		}
+		if candle.Low < merged.Low {
+			merged.Low = candle.Low
+		}
+		merged.Close = candle.Close
+		merged.Volume += candle.Volume
+	}
+
+	return resampled
+}
+
+// PlaceOrder Fill the whole order at the current price, the fee being charged in quote currency like on Kraken
+func (m *Market) PlaceOrder(order exchange.Order) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	rules, ok := m.pairs[order.Pair]
+	if !ok {
+		return "", fmt.Errorf("the %s pair has no historical data", order.Pair)
+	}
+
+	price, err := m.price(order.Pair)
+	if err != nil {
+		return "", err
+	}
+
+	value := order.Volume * price
+	fee := value * m.fee() / 100
+
+	switch order.Side {
+	case exchange.Buy:
+		if m.balances[rules.Quote] < value+fee {
+			return "", fmt.Errorf("insufficient funds : %.2f %s held, %.2f required", m.balances[rules.Quote], rules.Quote, value+fee)
+		}
+	case exchange.Sell:
+		if m.balances[rules.Base] < order.Volume {
+			return "", fmt.Errorf("insufficient funds : %f %s held, %f required", m.balances[rules.Base], rules.Base, order.Volume)
+		}
+	default:
+		return "", fmt.Errorf("the %s order side is unknown", order.Side)
+	}
+
+	if order.Validate {
+		return "", nil
+	}
+
+	if order.Side == exchange.Buy {
+		m.balances[rules.Quote] -= value + fee
+		m.balances[rules.Base] += order.Volume
+	} else {
+		m.balances[rules.Base] -= order.Volume
+		m.balances[rules.Quote] += value - fee
+	}
+
+	order.Id = fmt.Sprintf("BACKTEST-%05d", len(m.orders)+1)
+	order.Status = exchange.Closed
+	order.Price = price
+	order.Filled = order.Volume
+	order.Fee = fee / price
+	m.orders[order.Id] = order
+	m.trades = append(m.trades, trade{time: m.now, value: value})
+
+	return order.Id, nil
+}
+
+func (m *Market) QueryOrder(id string) (exchange.Order, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	order, ok := m.orders[id]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("the %s order is unknown", id)
+	}
+
+	return order, nil
+}
+
+// CancelOrder Always fail, the simulated orders being filled as soon as they are placed
+func (m *Market) CancelOrder(id string) error {
+	order, err := m.QueryOrder(id)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("the %s order is %s and cannot be canceled", id, order.Status)
+}
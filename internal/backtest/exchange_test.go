@@ -0,0 +1,101 @@
+package backtest
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTakerFee(t *testing.T) {
+	cases := []struct {
+		volume float64
+		fee    float64
+	}{
+		{0, 0.26},
+		{49999, 0.26},
+		{50000, 0.24},
+		{300000, 0.20},
+		{20000000, 0.10},
+	}
+
+	for _, c := range cases {
+		if fee := TakerFee(c.volume); fee != c.fee {
+			t.Errorf("The %f volume fee is %f instead of %f", c.volume, fee, c.fee)
+		}
+	}
+}
+
+func TestPairRules(t *testing.T) {
+	cases := []struct {
+		pair string
+		base string
+	}{
+		{"XXBTZEUR", "XXBT"},
+		{"DOTEUR", "DOT"},
+	}
+
+	for _, c := range cases {
+		if rules := pairRules(c.pair, "ZEUR"); rules.Base != c.base || rules.Quote != "ZEUR" {
+			t.Errorf("The %s rules are %+v", c.pair, rules)
+		}
+	}
+}
+
+func TestMarketCandles(t *testing.T) {
+	hourly := make([]domain.Candle, 48)
+	for index := range hourly {
+		price := float64(index + 1)
+		hourly[index] = domain.Candle{Time: start.Add(time.Duration(index) * time.Hour), Open: price, High: price + 0.5, Low: price - 0.5, Close: price, Volume: 1}
+	}
+
+	market := NewMarket(map[string][]domain.Candle{"XXBTZEUR": hourly}, "ZEUR", 0)
+	market.SetTime(start.Add(36*time.Hour + 30*time.Minute))
+
+	price, err := market.Price("XXBTZEUR")
+	if err != nil || price != 37 {
+		t.Errorf("The price is %f instead of the running candle open (%v)", price, err)
+	}
+
+	candles, err := market.Candles("XXBTZEUR", 60)
+	if err != nil || len(candles) != 36 || candles[35].Close != 36 {
+		t.Errorf("The finished hourly candles are %v (%v)", candles, err)
+	}
+
+	candles, err = market.Candles("XXBTZEUR", 1440)
+	if err != nil || len(candles) != 2 {
+		t.Fatalf("The daily candles are %v (%v)", candles, err)
+	}
+	if candles[0].Open != 1 || candles[0].Close != 24 || candles[0].High != 24.5 || candles[0].Low != 0.5 || candles[0].Volume != 24 {
+		t.Errorf("The first daily candle is %+v", candles[0])
+	}
+	if !candles[1].Time.Equal(start.AddDate(0, 0, 1)) || candles[1].Close != 36 {
+		t.Errorf("The running daily candle is %+v", candles[1])
+	}
+}
+
+func TestMarketOrders(t *testing.T) {
+	market := NewMarket(map[string][]domain.Candle{"XXBTZEUR": dailyCandles(100)}, "ZEUR", 100)
+	market.SetTime(start)
+
+	_, err := market.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 1})
+	if err == nil || !strings.HasPrefix(err.Error(), "insufficient funds") {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	id, err := market.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 0.5})
+	if err != nil || id != "BACKTEST-00001" {
+		t.Fatalf("The order %s was not placed : %v", id, err)
+	}
+
+	balances, _ := market.Balances()
+	if balances["ZEUR"] != 100-50-0.13 || balances["XXBT"] != 0.5 {
+		t.Errorf("The balances are %v", balances)
+	}
+
+	order, err := market.QueryOrder(id)
+	if err != nil || order.Status != exchange.Closed || order.Price != 100 || order.Fee != 0.0013 {
+		t.Errorf("The order is %+v (%v)", order, err)
+	}
+}
@@ -0,0 +1,31 @@
+package backtest
+
+// feeTier is a Kraken taker fee percentage applying from a 30-day trading volume
+type feeTier struct {
+	volume float64
+	fee    float64
+}
+
+// feeSchedule is the Kraken taker fee schedule, by decreasing 30-day volume
+var feeSchedule = []feeTier{
+	{10000000, 0.10},
+	{5000000, 0.12},
+	{2500000, 0.14},
+	{1000000, 0.16},
+	{500000, 0.18},
+	{250000, 0.20},
+	{100000, 0.22},
+	{50000, 0.24},
+	{0, 0.26},
+}
+
+// TakerFee Get the Kraken taker fee percentage of an account which traded `volume` over the last 30 days
+func TakerFee(volume float64) float64 {
+	for _, tier := range feeSchedule {
+		if volume >= tier.volume {
+			return tier.fee
+		}
+	}
+
+	return feeSchedule[len(feeSchedule)-1].fee
+}
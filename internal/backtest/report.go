@@ -0,0 +1,168 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"kraken-dca-bot/internal/domain"
+	"text/tabwriter"
+	"time"
+)
+
+// PairReport is the outcome of the replayed rounds for a pair, the amounts being in quote currency
+type PairReport struct {
+	Pair string
+	// Performance is the invested amount, the proceeds and the holdings value at the end of the period
+	domain.Performance
+	Fees float64
+	// Bought is the base asset volume purchased
+	Bought float64
+	// Holdings is the base asset volume held at the end of the period
+	Holdings  float64
+	Purchases int
+	Sales     int
+	Failures  int
+
+	transactions []domain.Transaction
+}
+
+// AverageCost Get the average price paid for the base asset, fees included
+func (p PairReport) AverageCost() float64 {
+	if p.Bought == 0 {
+		return 0
+	}
+
+	return p.Invested / p.Bought
+}
+
+// Report is the outcome of a backtest
+type Report struct {
+	From   time.Time
+	To     time.Time
+	Rounds int
+	Pairs  []PairReport
+	// MaxDrawdown is the largest decline in percent of the return multiple, i.e. the value and proceeds over the
+	// invested amount, from its peak
+	MaxDrawdown float64
+
+	peak float64
+}
+
+func newReport(pairs []string, from time.Time, to time.Time) Report {
+	report := Report{From: from, To: to}
+	for _, pair := range pairs {
+		report.Pairs = append(report.Pairs, PairReport{Pair: pair})
+	}
+
+	return report
+}
+
+// add Account for the transactions of a round
+func (r *Report) add(transactions []*domain.Transaction) {
+	r.Rounds++
+
+	for _, transaction := range transactions {
+		pair := r.pair(transaction.Pair)
+		if pair == nil || transaction.SkipReason != "" || transaction.IsStake() {
+			continue
+		}
+
+		pair.transactions = append(pair.transactions, *transaction)
+		switch {
+		case transaction.Exception != nil:
+			pair.Failures++
+		case transaction.IsSell():
+			pair.Sales++
+			pair.Fees += transaction.Fee * transaction.MarketPrice
+			pair.Holdings -= transaction.Amount
+		default:
+			pair.Purchases++
+			pair.Fees += transaction.Fee * transaction.MarketPrice
+			pair.Bought += transaction.Amount
+			pair.Holdings += transaction.Amount
+		}
+	}
+}
+
+func (r *Report) pair(name string) *PairReport {
+	for index := range r.Pairs {
+		if r.Pairs[index].Pair == name {
+			return &r.Pairs[index]
+		}
+	}
+
+	return nil
+}
+
+// value Get the performance of the pairs at the market prices and update the drawdown
+func (r *Report) value(market *Market) error {
+	for index := range r.Pairs {
+		pair := &r.Pairs[index]
+		price, err := market.Price(pair.Pair)
+		if err != nil {
+			return err
+		}
+
+		pair.Performance = domain.NewPerformance(pair.Pair, pair.transactions, map[string]float64{pair.Pair: price})
+	}
+
+	if r.Invested() == 0 {
+		return nil
+	}
+
+	multiple := (r.Value() + r.Proceeds()) / r.Invested()
+	if multiple > r.peak {
+		r.peak = multiple
+	}
+	if drawdown := (1 - multiple/r.peak) * 100; drawdown > r.MaxDrawdown {
+		r.MaxDrawdown = drawdown
+	}
+
+	return nil
+}
+
+// Invested Get the amount spent by all the purchases, fees included
+func (r Report) Invested() float64 {
+	return r.sum(func(pair PairReport) float64 { return pair.Invested })
+}
+
+// Proceeds Get the amount received by all the sales, fees deducted
+func (r Report) Proceeds() float64 {
+	return r.sum(func(pair PairReport) float64 { return pair.Proceeds })
+}
+
+// Fees Get the fees paid by all the orders
+func (r Report) Fees() float64 {
+	return r.sum(func(pair PairReport) float64 { return pair.Fees })
+}
+
+// Value Get the value of all the holdings at the end of the period
+func (r Report) Value() float64 {
+	return r.sum(func(pair PairReport) float64 { return pair.Value })
+}
+
+func (r Report) sum(field func(pair PairReport) float64) float64 {
+	total := 0.0
+	for _, pair := range r.Pairs {
+		total += field(pair)
+	}
+
+	return total
+}
+
+// Write Print the report as a table with the totals then a line per pair
+func (r Report) Write(writer io.Writer) error {
+	fmt.Fprintf(writer, "Backtest from %s to %s (%d rounds)\n\n", r.From.UTC().Format("2006-01-02"), r.To.UTC().Format("2006-01-02"), r.Rounds)
+	fmt.Fprintf(writer, "Total invested : %.2f\n", r.Invested())
+	fmt.Fprintf(writer, "Total proceeds : %.2f\n", r.Proceeds())
+	fmt.Fprintf(writer, "Final value    : %.2f\n", r.Value())
+	fmt.Fprintf(writer, "Fees           : %.2f\n", r.Fees())
+	fmt.Fprintf(writer, "Max drawdown   : %.2f%%\n\n", r.MaxDrawdown)
+
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "Pair\tInvested\tProceeds\tAverage cost\tHoldings\tValue\tPurchases\tSales\tFailures\t")
+	for _, pair := range r.Pairs {
+		fmt.Fprintf(table, "%s\t%.2f\t%.2f\t%.2f\t%f\t%.2f\t%d\t%d\t%d\t\n", pair.Pair, pair.Invested, pair.Proceeds, pair.AverageCost(), pair.Holdings, pair.Value, pair.Purchases, pair.Sales, pair.Failures)
+	}
+
+	return table.Flush()
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "math"
+
+// BalancePercentage invests a `Percentage` of the quote currency balance, bounded by the `Min` and `Max` amounts
+type BalancePercentage struct {
+	Percentage float64 `yaml:"percentage"`
+	Min        float64 `yaml:"min"`
+	Max        float64 `yaml:"max"`
+}
+
+// Amount Get the amount to invest given the quote currency balance
+func (b BalancePercentage) Amount(balance float64) float64 {
+	amount := math.Max(balance*b.Percentage/100, b.Min)
+	if b.Max > 0 {
+		amount = math.Min(amount, b.Max)
+	}
+
+	return amount
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "testing"
+
+func TestBalancePercentageAmount(t *testing.T) {
+	balancePercentage := BalancePercentage{Percentage: 10, Min: 5, Max: 100}
+
+	cases := []struct {
+		balance float64
+		amount  float64
+	}{
+		{500, 50},
+		{20, 5},
+		{3000, 100},
+	}
+
+	for _, c := range cases {
+		amount := balancePercentage.Amount(c.balance)
+		if amount != c.amount {
+			t.Errorf("The amount for a %f balance is %f instead of %f", c.balance, amount, c.amount)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PriceHistory Get the price of the pair at the date, false if it's unknown
+type PriceHistory func(pair string, date time.Time) (float64, bool)
+
+// Benchmark compares the DCA purchases of a pair, or of all of them, with a lump-sum purchase of the same total on the
+// first purchase date. The amounts are in quote currency.
+type Benchmark struct {
+	// Pair is empty for the benchmark of all the pairs
+	Pair  string
+	First time.Time
+	// Performance is the invested amount, the proceeds and the current value of the DCA
+	Performance
+	// LumpSum is the current value of the volume a lump-sum purchase of the invested amount would have bought
+	LumpSum float64
+	// XIRR is the annual money-weighted return of the DCA, NaN when it has none
+	XIRR float64
+	// TWR is the time-weighted return of the DCA over the whole period, NaN when it has none
+	TWR float64
+}
+
+// Advantage Get how much more the DCA is worth than the lump-sum purchase, proceeds included
+func (b Benchmark) Advantage() float64 {
+	return b.Value + b.Proceeds - b.LumpSum
+}
+
+// NewBenchmarks Get the benchmark of every pair of the executed transactions, by first purchase date, then the one of
+// all the pairs. The holdings are valued at the current `prices`, and at the `history` ones in the past, the price of
+// the latest transaction of a pair being used when its history is unknown.
+func NewBenchmarks(transactions []Transaction, now time.Time, prices map[string]float64, history PriceHistory) []Benchmark {
+	var executed []Transaction
+	for _, transaction := range transactions {
+		if transaction.Exception == nil && transaction.SkipReason == "" && transaction.Id != "" && transaction.Id != StagedTransactionId && !transaction.IsStake() {
+			executed = append(executed, transaction)
+		}
+	}
+	sort.SliceStable(executed, func(i, j int) bool {
+		return executed[i].Date.Before(executed[j].Date)
+	})
+
+	var pairs []string
+	byPair := map[string][]Transaction{}
+	for _, transaction := range executed {
+		if _, ok := byPair[transaction.Pair]; !ok {
+			pairs = append(pairs, transaction.Pair)
+		}
+		byPair[transaction.Pair] = append(byPair[transaction.Pair], transaction)
+	}
+
+	var benchmarks []Benchmark
+	for _, pair := range pairs {
+		benchmark := newBenchmark(byPair[pair], now, prices, history)
+		benchmark.Pair = pair
+		benchmarks = append(benchmarks, benchmark)
+	}
+	if len(executed) > 0 {
+		benchmarks = append(benchmarks, newBenchmark(executed, now, prices, history))
+	}
+
+	return benchmarks
+}
+
+// newBenchmark Get the benchmark of the transactions, sorted by date
+func newBenchmark(transactions []Transaction, now time.Time, prices map[string]float64, history PriceHistory) Benchmark {
+	benchmark := Benchmark{First: transactions[0].Date, Performance: NewPerformance("", transactions, prices)}
+	held := map[string]float64{}
+	invested := map[string]float64{}
+	// feeRates are the fees of the first purchase of each pair, charged to the lump-sum purchase
+	feeRates := map[string]float64{}
+	latest := map[string]float64{}
+
+	// price Get the price of the pair at the date, the one of the transactions executed at the date first
+	price := func(pair string, date time.Time, executed map[string]float64) float64 {
+		if value, ok := executed[pair]; ok {
+			return value
+		}
+		if value, ok := history(pair, date); ok {
+			return value
+		}
+
+		return latest[pair]
+	}
+	value := func(date time.Time, executed map[string]float64) float64 {
+		total := 0.0
+		for pair, volume := range held {
+			total += volume * price(pair, date, executed)
+		}
+
+		return total
+	}
+
+	var flows []CashFlow
+	var periods []Period
+	valueAfter := 0.0
+	for start := 0; start < len(transactions); {
+		date := transactions[start].Date
+		end := start
+		executed := map[string]float64{}
+		for end < len(transactions) && transactions[end].Date.Equal(date) {
+			executed[transactions[end].Pair] = transactions[end].MarketPrice
+			end++
+		}
+
+		periods = append(periods, Period{Start: valueAfter, End: value(date, executed)})
+		for _, transaction := range transactions[start:end] {
+			latest[transaction.Pair] = transaction.MarketPrice
+			if transaction.IsSell() {
+				held[transaction.Pair] -= transaction.Amount
+				flows = append(flows, CashFlow{Date: date, Amount: transaction.Proceeds()})
+
+				continue
+			}
+
+			if _, ok := feeRates[transaction.Pair]; !ok && transaction.Amount > 0 {
+				feeRates[transaction.Pair] = transaction.Fee / transaction.Amount
+			}
+			held[transaction.Pair] += transaction.Amount
+			invested[transaction.Pair] += transaction.Cost()
+			flows = append(flows, CashFlow{Date: date, Amount: -transaction.Cost()})
+		}
+		valueAfter = value(date, executed)
+		start = end
+	}
+
+	periods = append(periods, Period{Start: valueAfter, End: benchmark.Value})
+	flows = append(flows, CashFlow{Date: now, Amount: benchmark.Value})
+
+	for pair, amount := range invested {
+		benchmark.LumpSum += amount / firstPrice(transactions, pair, history) / (1 + feeRates[pair]) * prices[pair]
+	}
+
+	var err error
+	benchmark.XIRR, err = XIRR(flows)
+	if err != nil {
+		benchmark.XIRR = math.NaN()
+	}
+	benchmark.TWR, err = TWR(periods)
+	if err != nil {
+		benchmark.TWR = math.NaN()
+	}
+
+	return benchmark
+}
+
+// firstPrice Get the price of the pair at the date of the first transaction : the one of its transaction at that date,
+// else its history one, else the one of its first transaction
+func firstPrice(transactions []Transaction, pair string, history PriceHistory) float64 {
+	first := transactions[0].Date
+	for _, transaction := range transactions {
+		if !transaction.Date.Equal(first) {
+			break
+		}
+		if transaction.Pair == pair {
+			return transaction.MarketPrice
+		}
+	}
+
+	if price, ok := history(pair, first); ok {
+		return price
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Pair == pair {
+			return transaction.MarketPrice
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewBenchmarks(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	transaction := func(pair string, date time.Time, price float64, amount float64) Transaction {
+		transaction := NewTransaction(pair).Complete("TX", price, amount, 0)
+		transaction.Date = date
+
+		return *transaction
+	}
+
+	transactions := []Transaction{
+		transaction("XXBTZEUR", start.Add(year), 50, 2),
+		transaction("XXBTZEUR", start, 100, 1),
+		transaction("XETHZEUR", start, 10, 10),
+		*NewTransaction("XETHZEUR").Fail(errors.New("order failed")),
+	}
+	prices := map[string]float64{"XXBTZEUR": 100, "XETHZEUR": 20}
+	history := func(string, time.Time) (float64, bool) {
+		return 0, false
+	}
+
+	benchmarks := NewBenchmarks(transactions, start.Add(year), prices, history)
+	if len(benchmarks) != 3 || benchmarks[0].Pair != "XXBTZEUR" || benchmarks[1].Pair != "XETHZEUR" || benchmarks[2].Pair != "" {
+		t.Fatalf("The benchmarks are %+v", benchmarks)
+	}
+
+	bitcoin := benchmarks[0]
+	if bitcoin.Invested != 200 || bitcoin.Value != 300 || bitcoin.LumpSum != 200 || bitcoin.Advantage() != 100 {
+		t.Errorf("The bitcoin benchmark is %+v", bitcoin)
+	}
+	if math.Abs(bitcoin.XIRR-1) > 1e-6 || math.Abs(bitcoin.TWR) > 1e-9 {
+		t.Errorf("The bitcoin returns are %f and %f", bitcoin.XIRR, bitcoin.TWR)
+	}
+
+	all := benchmarks[2]
+	if all.Invested != 300 || all.Value != 500 || all.LumpSum != 400 || !all.First.Equal(start) {
+		t.Errorf("The overall benchmark is %+v", all)
+	}
+
+	// The ether price is known a year later, when the bitcoin is bought again
+	history = func(pair string, date time.Time) (float64, bool) {
+		return 5, pair == "XETHZEUR" && date.Equal(start.Add(year))
+	}
+	all = NewBenchmarks(transactions, start.Add(year), prices, history)[2]
+	// 200 after the first purchases, down to 100 before the second one, then 200 after it up to 500
+	if math.Abs(all.TWR-(100.0/200*500/200-1)) > 1e-9 {
+		t.Errorf("The overall time-weighted return is %f", all.TWR)
+	}
+}
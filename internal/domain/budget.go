@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Budget is a monthly amount split between pairs, the `Allocation` mapping each pair to its share in percent
+type Budget struct {
+	Monthly    float64            `yaml:"monthly"`
+	Allocation map[string]float64 `yaml:"allocation"`
+}
+
+// Allocates Check whether the budget allocates a share to the pair
+func (b Budget) Allocates(pair string) bool {
+	return b.Allocation[pair] > 0
+}
+
+// RoundsLeftInMonth Get the number of rounds scheduled until the end of the month, the round happening at `now` included
+func RoundsLeftInMonth(now time.Time, period time.Duration) int {
+	if period <= 0 {
+		return 1
+	}
+
+	year, month, _ := now.Date()
+	endOfMonth := time.Date(year, month+1, 1, 0, 0, 0, 0, now.Location())
+
+	return int(math.Ceil(float64(endOfMonth.Sub(now)) / float64(period)))
+}
+
+// Amount Get the amount to invest in the pair during the round happening at `now`, given the amount already `spent`
+// on it this month. The remaining monthly share is spread evenly over the rounds left in the month, so that failed
+// rounds are caught up by the next ones.
+func (b Budget) Amount(pair string, spent float64, now time.Time, period time.Duration) float64 {
+	return b.Remaining(pair, spent) / float64(RoundsLeftInMonth(now, period))
+}
+
+// Remaining Get the part of the pair monthly share left to invest given the amount already `spent` on it this month
+func (b Budget) Remaining(pair string, spent float64) float64 {
+	return math.Max(b.Monthly*b.Allocation[pair]/100-spent, 0)
+}
+
+// BudgetSpent Get the budgeted amount spent on the pair during the month of `now` by the successful buy transactions,
+// leaving out the deployment plans shares and the other amounts invested on top of the budget
+func BudgetSpent(transactions []Transaction, pair string, now time.Time) float64 {
+	spent := 0.0
+	year, month, _ := now.Date()
+	for _, transaction := range transactions {
+		if transaction.Exception != nil || transaction.Id == "" || transaction.Id == StagedTransactionId || !transaction.IsBuy() {
+			continue
+		}
+
+		date := transaction.Date.In(now.Location())
+		if transaction.Pair == pair && date.Year() == year && date.Month() == month {
+			spent += transaction.Budgeted
+		}
+	}
+
+	return spent
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoundsLeftInMonth(t *testing.T) {
+	day := 24 * time.Hour
+	cases := []struct {
+		now    time.Time
+		period time.Duration
+		rounds int
+	}{
+		{time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), day, 31},
+		{time.Date(2022, 10, 31, 12, 0, 0, 0, time.UTC), day, 1},
+		{time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC), 7 * day, 4},
+		{time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), 7 * day, 4},
+	}
+
+	for _, c := range cases {
+		rounds := RoundsLeftInMonth(c.now, c.period)
+		if rounds != c.rounds {
+			t.Errorf("The rounds left at %v are %d instead of %d", c.now, rounds, c.rounds)
+		}
+	}
+}
+
+func TestBudgetAmount(t *testing.T) {
+	budget := Budget{
+		Monthly:    300,
+		Allocation: map[string]float64{"XXBTZEUR": 60, "XETHZEUR": 40},
+	}
+	now := time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	cases := []struct {
+		pair   string
+		spent  float64
+		amount float64
+	}{
+		{"XXBTZEUR", 0, 45},
+		{"XXBTZEUR", 100, 20},
+		{"XETHZEUR", 120, 0},
+		{"ADAEUR", 0, 0},
+	}
+
+	for _, c := range cases {
+		amount := budget.Amount(c.pair, c.spent, now, week)
+		if amount != c.amount {
+			t.Errorf("The %s amount with %f spent is %f instead of %f", c.pair, c.spent, amount, c.amount)
+		}
+	}
+}
+
+func TestBudgetSpent(t *testing.T) {
+	now := time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{Id: "TX1", Pair: "XXBTZEUR", Side: Buy, Date: now, Budgeted: 45},
+		{Id: "TX2", Pair: "XXBTZEUR", Side: Buy, Date: now, Amount: 0.05, MarketPrice: 20000},
+		{Id: "TX3", Pair: "XXBTZEUR", Side: Buy, Date: now.AddDate(0, -1, 0), Budgeted: 45},
+		{Id: StagedTransactionId, Pair: "XXBTZEUR", Side: Buy, Date: now, Budgeted: 45},
+		{Id: "TX4", Pair: "XETHZEUR", Side: Buy, Date: now, Budgeted: 30},
+	}
+
+	spent := BudgetSpent(transactions, "XXBTZEUR", now)
+	if spent != 45 {
+		t.Errorf("The budget spent is %f instead of 45", spent)
+	}
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Candle is an OHLC entry of a pair price history
+type Candle struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// Highest Get the highest price reached by the given candles
+func Highest(candles []Candle) float64 {
+	high := 0.0
+	for _, candle := range candles {
+		if candle.High > high {
+			high = candle.High
+		}
+	}
+
+	return high
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Caps limits the amount spent per day, per calendar month and since the bot started investing.
+// A zero cap is not enforced.
+type Caps struct {
+	Daily    float64 `yaml:"daily"`
+	Monthly  float64 `yaml:"monthly"`
+	Lifetime float64 `yaml:"lifetime"`
+}
+
+// Spending is the amount already spent over each cap period
+type Spending struct {
+	Daily    float64
+	Monthly  float64
+	Lifetime float64
+}
+
+// Spent Get the amount spent at `now` by the successful buy transactions of the given pair, or of all pairs if `pair`
+// is empty
+func Spent(transactions []Transaction, pair string, now time.Time) Spending {
+	spending := Spending{}
+	year, month, day := now.Date()
+
+	for _, transaction := range transactions {
+		if transaction.Exception != nil || transaction.Id == "" || transaction.Id == StagedTransactionId || !transaction.IsBuy() {
+			continue
+		}
+
+		if pair != "" && transaction.Pair != pair {
+			continue
+		}
+
+		cost := transaction.Cost()
+		spending.Lifetime += cost
+
+		date := transaction.Date.In(now.Location())
+		if date.Year() == year && date.Month() == month {
+			spending.Monthly += cost
+
+			if date.Day() == day {
+				spending.Daily += cost
+			}
+		}
+	}
+
+	return spending
+}
+
+// Check Get an error if spending `amount` on top of `spending` exceeds one of the caps
+func (c Caps) Check(spending Spending, amount float64) error {
+	caps := []struct {
+		name  string
+		cap   float64
+		spent float64
+	}{
+		{"daily", c.Daily, spending.Daily},
+		{"monthly", c.Monthly, spending.Monthly},
+		{"lifetime", c.Lifetime, spending.Lifetime},
+	}
+
+	for _, limit := range caps {
+		if limit.cap > 0 && limit.spent+amount > limit.cap {
+			return fmt.Errorf("the %s cap of %.2f would be exceeded : %.2f already spent, %.2f requested", limit.name, limit.cap, limit.spent, amount)
+		}
+	}
+
+	return nil
+}
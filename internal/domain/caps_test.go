@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpent(t *testing.T) {
+	now := time.Date(2022, 10, 12, 15, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{Id: "TX1", Pair: "XXBTZEUR", Date: now.Add(-time.Hour), MarketPrice: 100, Amount: 0.09, Fee: 0.01},
+		{Id: "TX2", Pair: "XETHZEUR", Date: now.Add(-2 * time.Hour), MarketPrice: 10, Amount: 2},
+		{Id: "TX3", Pair: "XXBTZEUR", Date: now.Add(-5 * 24 * time.Hour), MarketPrice: 100, Amount: 0.5},
+		{Id: "TX4", Pair: "XXBTZEUR", Date: now.Add(-30 * 24 * time.Hour), MarketPrice: 100, Amount: 1},
+		{Id: StagedTransactionId, Pair: "XXBTZEUR", Date: now, MarketPrice: 100, Amount: 1},
+		{Id: "TX5", Pair: "XXBTZEUR", Side: Sell, Date: now, MarketPrice: 100, Amount: 1},
+		{Id: "ETH-FLEX", Pair: "XXBTZEUR", Side: Stake, Date: now, MarketPrice: 100, Amount: 1},
+	}
+
+	spending := Spent(transactions, "", now)
+	if spending != (Spending{Daily: 30, Monthly: 80, Lifetime: 180}) {
+		t.Errorf("The overall spending is %v", spending)
+	}
+
+	spending = Spent(transactions, "XXBTZEUR", now)
+	if spending != (Spending{Daily: 10, Monthly: 60, Lifetime: 160}) {
+		t.Errorf("The XXBTZEUR spending is %v", spending)
+	}
+}
+
+func TestCapsCheck(t *testing.T) {
+	caps := Caps{Daily: 50, Monthly: 200}
+	spending := Spending{Daily: 30, Monthly: 120, Lifetime: 1000}
+
+	if err := caps.Check(spending, 20); err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	err := caps.Check(spending, 25)
+	if err == nil || err.Error() != "the daily cap of 50.00 would be exceeded : 30.00 already spent, 25.00 requested" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
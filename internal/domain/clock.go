@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/xhit/go-str2duration/v2"
+	"time"
+)
+
+// Clock defaults
+const (
+	DefaultClockWarnDrift = "5s"
+	DefaultClockMaxDrift  = "30s"
+	DefaultClockInterval  = "1h"
+)
+
+// Clock is the check of the local clock against the exchange time, at startup, before every round and every
+// `Interval`. A drift above `WarnDrift` is logged, and no round is run while it's above `MaxDrift`.
+type Clock struct {
+	WarnDrift string `yaml:"warnDrift"`
+	MaxDrift  string `yaml:"maxDrift"`
+	Interval  string `yaml:"interval"`
+}
+
+// Drifts Get the drift above which a warning is logged and the one above which trading is refused
+func (c Clock) Drifts() (time.Duration, time.Duration, error) {
+	warnDrift, err := str2duration.ParseDuration(c.WarnDrift)
+	if err != nil {
+		return 0, 0, fmt.Errorf("the clock warning drift cannot be parsed : %w", err)
+	}
+
+	maxDrift, err := str2duration.ParseDuration(c.MaxDrift)
+	if err != nil {
+		return 0, 0, fmt.Errorf("the clock max drift cannot be parsed : %w", err)
+	}
+
+	if warnDrift < 0 || maxDrift < warnDrift {
+		return 0, 0, fmt.Errorf("the clock warning drift must be positive and below the max drift")
+	}
+
+	return warnDrift, maxDrift, nil
+}
+
+// CheckInterval Get the duration between two periodic clock checks
+func (c Clock) CheckInterval() (time.Duration, error) {
+	interval, err := str2duration.ParseDuration(c.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("the clock check interval cannot be parsed : %w", err)
+	}
+
+	if interval <= 0 {
+		return 0, fmt.Errorf("the clock check interval must be positive")
+	}
+
+	return interval, nil
+}
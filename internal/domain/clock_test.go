@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockDrifts(t *testing.T) {
+	warnDrift, maxDrift, err := Clock{WarnDrift: "2s", MaxDrift: "1m"}.Drifts()
+	if err != nil || warnDrift != 2*time.Second || maxDrift != time.Minute {
+		t.Errorf("The drifts are %v and %v (%v)", warnDrift, maxDrift, err)
+	}
+
+	for _, clock := range []Clock{{WarnDrift: "soon", MaxDrift: "1m"}, {WarnDrift: "2s", MaxDrift: "later"}, {WarnDrift: "2m", MaxDrift: "1m"}} {
+		if _, _, err = clock.Drifts(); err == nil {
+			t.Errorf("The %+v clock drifts should be invalid", clock)
+		}
+	}
+}
+
+func TestClockCheckInterval(t *testing.T) {
+	interval, err := Clock{Interval: "1d"}.CheckInterval()
+	if err != nil || interval != 24*time.Hour {
+		t.Errorf("The interval is %v (%v)", interval, err)
+	}
+
+	if _, err = (Clock{Interval: "0s"}).CheckInterval(); err == nil {
+		t.Error("The zero interval should be invalid")
+	}
+}
@@ -3,25 +3,61 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"github.com/xhit/go-str2duration/v2"
 	"gopkg.in/yaml.v3"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
+	// Exchange is the name of the exchange the account is on, "kraken" by default
+	Exchange string `yaml:"exchange"`
+	// Paper simulates the trading instead of placing real orders
+	Paper  *Paper `yaml:"paper"`
 	Kraken Kraken `yaml:"kraken"`
 	Smtp   Smtp   `yaml:"smtp"`
 
-	Notify    string    `yaml:"notify"`
+	Notify string `yaml:"notify"`
+	// Summary sends a summary notification after every investment round
+	Summary   bool      `yaml:"summary"`
 	Frequency string    `yaml:"frequency"`
 	Currency  string    `yaml:"currency"`
 	Pairs     []DCAPair `yaml:"pairs"`
+
+	// Storage is the path of the file persisting the transaction history
+	Storage string `yaml:"storage"`
+	// State is the path of the file persisting the values remembered between rounds
+	State string `yaml:"state"`
+	// MarketData is the directory of the market data store the drawdown and the indicators read the candles from,
+	// the exchange serving them when empty
+	MarketData string `yaml:"marketData"`
+	Caps       Caps   `yaml:"caps"`
+
+	Deployments []DeploymentPlan `yaml:"deployments"`
+	Budget      *Budget          `yaml:"budget"`
+	Deposits    *Deposits        `yaml:"deposits"`
+	Webhook     *Webhook         `yaml:"webhook"`
+
+	// Sells are the assets gradually sold every round
+	Sells       []SellPair   `yaml:"sells"`
+	Withdrawals *Withdrawals `yaml:"withdrawals"`
+	// Shadows are the strategies run on paper next to the live one to compare them
+	Shadows *Shadows `yaml:"shadows"`
+	// Recheck is the re-check policy of the rounds deferred while trading is restricted
+	Recheck Recheck `yaml:"recheck"`
+	// Clock is the check of the local clock drift from the exchange time
+	Clock Clock `yaml:"clock"`
 }
 
 type Kraken struct {
 	Key    string `yaml:"key"`
 	Secret string `yaml:"secret"`
+	// Feed streams the prices from the WebSocket API
+	Feed *Feed `yaml:"feed"`
 }
 
 type Smtp struct {
@@ -35,6 +71,49 @@ type Smtp struct {
 type DCAPair struct {
 	Pair   string  `json:"pair"`
 	Amount float64 `json:"amount"`
+
+	ValueAveraging *ValueAveraging `yaml:"valueAveraging"`
+	Goal           *Goal           `yaml:"goal"`
+	// BalancePercentage replaces the fixed amount by a percentage of the quote currency balance
+	BalancePercentage *BalancePercentage `yaml:"balancePercentage"`
+	Drawdown          *Drawdown          `yaml:"drawdown"`
+	Indicators        *Indicators        `yaml:"indicators"`
+	PriceRules        *PriceRules        `yaml:"priceRules"`
+	TakeProfit        *TakeProfit        `yaml:"takeProfit"`
+	Staking           *Staking           `yaml:"staking"`
+	Caps              *Caps              `yaml:"caps"`
+}
+
+// Period Get the duration between two investment rounds
+func (c Config) Period() (time.Duration, error) {
+	return str2duration.ParseDuration(c.Frequency)
+}
+
+// Scheduled Check whether the rounds run on the `Frequency` schedule, which is optional when the deposits trigger the
+// rounds
+func (c Config) Scheduled() bool {
+	return c.Frequency != "" || c.Deposits == nil
+}
+
+// TradedPairs Get the pairs bought or sold, in the configuration order
+func (c Config) TradedPairs() []string {
+	var pairs []string
+	seen := map[string]bool{}
+	add := func(pair string) {
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	for _, pair := range c.Pairs {
+		add(pair.Pair)
+	}
+	for _, sell := range c.Sells {
+		add(sell.Pair)
+	}
+
+	return pairs
 }
 
 func ParseConfig(path string) (*Config, error) {
@@ -62,13 +141,247 @@ func ParseConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("an error occurred while unmarshalling the configuration Yaml : %w", err)
 	}
 
-	if config.Kraken.Key == "" {
+	if config.Exchange == "" {
+		config.Exchange = "kraken"
+	}
+
+	// Paper trading only needs the public market data
+	krakenAccount := config.Exchange == "kraken" && config.Paper == nil
+	if krakenAccount && config.Kraken.Key == "" {
 		return nil, errors.New("the kraken key is not specified")
 	}
 
-	if config.Kraken.Secret == "" {
+	if krakenAccount && config.Kraken.Secret == "" {
 		return nil, errors.New("the kraken secret is not specified")
 	}
 
+	err = config.validateAllocations()
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.validateStrategies()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range config.Pairs {
+		if pair.TakeProfit != nil {
+			err = pair.TakeProfit.Validate()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, sell := range config.Sells {
+		err = sell.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Webhook != nil {
+		err = config.Webhook.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Withdrawals != nil {
+		err = config.Withdrawals.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Kraken.Feed != nil {
+		if config.Kraken.Feed.Url == "" {
+			config.Kraken.Feed.Url = DefaultFeedUrl
+		}
+
+		if config.Kraken.Feed.Staleness == "" {
+			config.Kraken.Feed.Staleness = DefaultFeedStaleness
+		}
+
+		_, err = config.Kraken.Feed.StalenessDuration()
+		if err != nil {
+			return nil, fmt.Errorf("the feed staleness is invalid : %w", err)
+		}
+	}
+
+	if config.Shadows != nil {
+		if config.Shadows.Report == "" {
+			config.Shadows.Report = DefaultShadowReport
+		}
+
+		err = config.Shadows.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Recheck.Delay == "" {
+		config.Recheck.Delay = DefaultRecheckDelay
+	}
+
+	if config.Recheck.MaxDelay == "" {
+		config.Recheck.MaxDelay = DefaultRecheckMaxDelay
+	}
+
+	_, _, err = config.Recheck.Delays()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Clock.WarnDrift == "" {
+		config.Clock.WarnDrift = DefaultClockWarnDrift
+	}
+
+	if config.Clock.MaxDrift == "" {
+		config.Clock.MaxDrift = DefaultClockMaxDrift
+	}
+
+	if config.Clock.Interval == "" {
+		config.Clock.Interval = DefaultClockInterval
+	}
+
+	_, _, err = config.Clock.Drifts()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = config.Clock.CheckInterval()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Paper != nil {
+		if config.Paper.Portfolio == "" {
+			config.Paper.Portfolio = "paper.json"
+		}
+
+		if config.Paper.Fee == 0 {
+			config.Paper.Fee = DefaultPaperFee
+		}
+
+		// The simulated history is kept apart from the real one
+		if config.Storage == "" {
+			config.Storage = "paper-history.json"
+		}
+
+		if config.State == "" {
+			config.State = "paper-state.json"
+		}
+	}
+
+	if config.Storage == "" {
+		config.Storage = "history.json"
+	}
+
+	if config.State == "" {
+		config.State = "state.json"
+	}
+
+	if config.Shadows != nil {
+		for index := range config.Shadows.Strategies {
+			config.Shadows.Strategies[index].setDefaults()
+		}
+	}
+
 	return &config, nil
 }
+
+// validateStrategies Check that the amount of each pair is set by a single strategy, as only one of them would be
+// applied
+func (c Config) validateStrategies() error {
+	for _, pair := range c.Pairs {
+		var strategies []string
+		if pair.ValueAveraging != nil {
+			strategies = append(strategies, "valueAveraging")
+		}
+		if pair.Goal != nil {
+			strategies = append(strategies, "goal")
+		}
+		if c.Budget != nil && c.Budget.Allocates(pair.Pair) {
+			strategies = append(strategies, "budget")
+		}
+		if pair.BalancePercentage != nil {
+			strategies = append(strategies, "balancePercentage")
+		}
+
+		if len(strategies) > 1 {
+			return fmt.Errorf("the %s amount is set by several strategies : %s", pair.Pair, strings.Join(strategies, ", "))
+		}
+	}
+
+	return nil
+}
+
+// validateAllocations Check that the deployment plans, the budget, the deposits and the webhook only target
+// configured pairs, the allocations adding up to 100%
+func (c Config) validateAllocations() error {
+	pairs := map[string]bool{}
+	for _, pair := range c.Pairs {
+		pairs[pair.Pair] = true
+	}
+
+	for _, plan := range c.Deployments {
+		if !plan.End.After(plan.Start) {
+			return fmt.Errorf("the %s deployment plan must end after it starts", plan.Name)
+		}
+
+		total := 0.0
+		for pair, percentage := range plan.Allocation {
+			if !pairs[pair] {
+				return fmt.Errorf("the %s deployment plan allocates to %s which is not a configured pair", plan.Name, pair)
+			}
+
+			total += percentage
+		}
+
+		if math.Abs(total-100) > 1e-9 {
+			return fmt.Errorf("the %s deployment plan allocates %.2f%% instead of 100%%", plan.Name, total)
+		}
+	}
+
+	if c.Budget != nil {
+		total := 0.0
+		for pair, percentage := range c.Budget.Allocation {
+			if !pairs[pair] {
+				return fmt.Errorf("the monthly budget allocates to %s which is not a configured pair", pair)
+			}
+
+			total += percentage
+		}
+
+		if math.Abs(total-100) > 1e-9 {
+			return fmt.Errorf("the monthly budget allocates %.2f%% instead of 100%%", total)
+		}
+	}
+
+	if c.Deposits != nil {
+		total := 0.0
+		for pair, percentage := range c.Deposits.Allocation {
+			if !pairs[pair] {
+				return fmt.Errorf("the deposits allocate to %s which is not a configured pair", pair)
+			}
+
+			total += percentage
+		}
+
+		if math.Abs(total-100) > 1e-9 {
+			return fmt.Errorf("the deposits allocate %.2f%% instead of 100%%", total)
+		}
+	}
+
+	if c.Webhook != nil {
+		for _, allowed := range c.Webhook.Pairs {
+			if !pairs[allowed.Pair] {
+				return fmt.Errorf("the webhook allows %s which is not a configured pair", allowed.Pair)
+			}
+		}
+	}
+
+	return nil
+}
@@ -27,6 +27,7 @@ func TestParseConfig(t *testing.T) {
 	}
 
 	expectedConfig := Config{
+		Exchange: "kraken",
 		Kraken: Kraken{
 			Key:    "fake_kraken_key",
 			Secret: "fake_kraken_secret",
@@ -42,12 +43,16 @@ func TestParseConfig(t *testing.T) {
 		Frequency: "1ms",
 		Currency:  "ZEUR",
 		Pairs: []DCAPair{
-			{"XETHZEUR", 20.00},
-			{"XXBTZEUR", 10.00},
-			{"XXRPZEUR", 10.00},
-			{"ADAEUR", 10.00},
-			{"USDTEUR", 10.00},
+			{Pair: "XETHZEUR", Amount: 20.00},
+			{Pair: "XXBTZEUR", Amount: 10.00},
+			{Pair: "XXRPZEUR", Amount: 10.00},
+			{Pair: "ADAEUR", Amount: 10.00},
+			{Pair: "USDTEUR", Amount: 10.00},
 		},
+		Storage: "history.json",
+		State:   "state.json",
+		Recheck: Recheck{Delay: DefaultRecheckDelay, MaxDelay: DefaultRecheckMaxDelay},
+		Clock:   Clock{WarnDrift: DefaultClockWarnDrift, MaxDrift: DefaultClockMaxDrift, Interval: DefaultClockInterval},
 	}
 
 	if !reflect.DeepEqual(*config, expectedConfig) {
@@ -82,3 +87,106 @@ func TestParseConfigEmptyKrakenSecretFail(t *testing.T) {
 		t.Errorf("An unexpected error occurred : %v", err)
 	}
 }
+
+func TestParseConfigInvalidDeploymentFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/invalid-deployment.yaml")
+	if err == nil || err.Error() != "the bonus deployment plan allocates to XETHZEUR which is not a configured pair" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestParseConfigInvalidDeploymentAllocationFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/invalid-deployment-allocation.yaml")
+	if err == nil || err.Error() != "the bonus deployment plan allocates 90.00% instead of 100%" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestParseConfigInvalidStrategiesFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/invalid-strategies.yaml")
+	if err == nil || err.Error() != "the XXBTZEUR amount is set by several strategies : goal, budget" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestParseConfigInvalidDepositsAllocationFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/invalid-deposits-allocation.yaml")
+	if err == nil || err.Error() != "the deposits allocate 150.00% instead of 100%" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestParseConfigInvalidBudgetAllocationFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/invalid-budget-allocation.yaml")
+	if err == nil || err.Error() != "the monthly budget allocates 120.00% instead of 100%" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestParseConfigEmptyWebhookSecretFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/empty-webhook-secret.yaml")
+	if err == nil || err.Error() != "the webhook secret is not specified" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestParseConfigPaper(t *testing.T) {
+	config, err := ParseConfig("../../test/data/paper.yaml")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	if config.Paper.Portfolio != "paper.json" || config.Paper.Fee != DefaultPaperFee || config.Paper.Balances["ZEUR"] != 1000 {
+		t.Errorf("The paper configuration is %+v", *config.Paper)
+	}
+
+	if config.Storage != "paper-history.json" || config.State != "paper-state.json" {
+		t.Errorf("The paper history is stored in %s and its state in %s", config.Storage, config.State)
+	}
+}
+
+func TestParseConfigFeed(t *testing.T) {
+	config, err := ParseConfig("../../test/data/feed.yaml")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	if config.Kraken.Feed.Url != DefaultFeedUrl || config.Kraken.Feed.Staleness != "30s" {
+		t.Errorf("The feed configuration is %+v", *config.Kraken.Feed)
+	}
+
+	if config.Recheck.Delay != DefaultRecheckDelay || config.Recheck.MaxDelay != DefaultRecheckMaxDelay {
+		t.Errorf("The recheck configuration is %+v", config.Recheck)
+	}
+}
+
+func TestParseConfigInvalidFeedStalenessFail(t *testing.T) {
+	_, err := ParseConfig("../../test/data/invalid-feed-staleness.yaml")
+	if err == nil || !strings.HasPrefix(err.Error(), "the feed staleness is invalid") {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestTradedPairs(t *testing.T) {
+	config := Config{
+		Pairs: []DCAPair{{Pair: "XXBTZEUR"}, {Pair: "XETHZEUR"}},
+		Sells: []SellPair{{Pair: "XETHZEUR"}, {Pair: "DOTEUR"}},
+	}
+
+	pairs := config.TradedPairs()
+	if strings.Join(pairs, ",") != "XXBTZEUR,XETHZEUR,DOTEUR" {
+		t.Errorf("The traded pairs are %v", pairs)
+	}
+}
+
+func TestParseConfigShadows(t *testing.T) {
+	config, err := ParseConfig("../../test/data/bot-shadows-config.yaml")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	shadow := config.Shadows.Strategies[0]
+	if shadow.Paper.Portfolio != "shadow-bitcoin-only-portfolio.json" || shadow.Storage != "shadow-bitcoin-only-history.json" || shadow.Paper.Fee != DefaultPaperFee || shadow.Pairs[0].Amount != 30 {
+		t.Errorf("The shadow strategy is %+v", shadow)
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/xhit/go-str2duration/v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Recheck defaults
+const (
+	DefaultRecheckDelay    = "1m"
+	DefaultRecheckMaxDelay = "1h"
+)
+
+// Recheck is the re-check policy of the rounds deferred while trading is restricted : the trading status is checked
+// again after `Delay`, the delay doubling after every check still restricted, up to `MaxDelay`
+type Recheck struct {
+	Delay    string `yaml:"delay"`
+	MaxDelay string `yaml:"maxDelay"`
+}
+
+// Delays Get the first and the maximum re-check delays
+func (r Recheck) Delays() (time.Duration, time.Duration, error) {
+	delay, err := str2duration.ParseDuration(r.Delay)
+	if err != nil {
+		return 0, 0, fmt.Errorf("the recheck delay cannot be parsed : %w", err)
+	}
+
+	maxDelay, err := str2duration.ParseDuration(r.MaxDelay)
+	if err != nil {
+		return 0, 0, fmt.Errorf("the recheck max delay cannot be parsed : %w", err)
+	}
+
+	if delay <= 0 || maxDelay < delay {
+		return 0, 0, fmt.Errorf("the recheck delay must be positive and below the max delay")
+	}
+
+	return delay, maxDelay, nil
+}
+
+// Deferral is an investment round postponed because the exchange, or some of the traded pairs, are not online, or
+// because the local clock drifts too much from the exchange time
+type Deferral struct {
+	Date time.Time
+	// System is the trading status of the exchange
+	System string
+	// Drift is the drift of the local clock from the exchange time, when above the maximum drift
+	Drift time.Duration
+	// Pairs are the trading statuses of the restricted pairs
+	Pairs map[string]string
+	// Retry is the date of the next trading status check
+	Retry time.Time
+}
+
+// Restricted Get the restrictions of the deferral, the exchange status and the clock drift first then the pair
+// statuses sorted by pair
+func (d Deferral) Restricted() []string {
+	var restrictions []string
+	if d.System != "" {
+		restrictions = append(restrictions, "exchange : "+d.System)
+	}
+
+	if d.Drift != 0 {
+		restrictions = append(restrictions, "clock drift : "+d.Drift.String())
+	}
+
+	var pairs []string
+	for pair, status := range d.Pairs {
+		pairs = append(pairs, pair+" : "+status)
+	}
+	sort.Strings(pairs)
+
+	return append(restrictions, pairs...)
+}
+
+func (d Deferral) String() string {
+	return fmt.Sprintf("Round deferred until %s (%s)", d.Retry.Format(time.RFC3339), strings.Join(d.Restricted(), ", "))
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecheckDelays(t *testing.T) {
+	delay, maxDelay, err := Recheck{Delay: "30s", MaxDelay: "2h"}.Delays()
+	if err != nil || delay != 30*time.Second || maxDelay != 2*time.Hour {
+		t.Errorf("The delays are %v and %v (%v)", delay, maxDelay, err)
+	}
+
+	for _, recheck := range []Recheck{{Delay: "soon", MaxDelay: "1h"}, {Delay: "1m", MaxDelay: "later"}, {Delay: "2h", MaxDelay: "1h"}, {Delay: "0s", MaxDelay: "1h"}} {
+		if _, _, err = recheck.Delays(); err == nil {
+			t.Errorf("The %+v recheck should be invalid", recheck)
+		}
+	}
+}
+
+func TestDeferralString(t *testing.T) {
+	deferral := Deferral{
+		Date:   time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+		System: "post_only",
+		Pairs:  map[string]string{"XXBTZEUR": "cancel_only", "XETHZEUR": "limit_only"},
+		Retry:  time.Date(2022, 6, 1, 12, 1, 0, 0, time.UTC),
+	}
+
+	expected := "Round deferred until 2022-06-01T12:01:00Z (exchange : post_only, XETHZEUR : limit_only, XXBTZEUR : cancel_only)"
+	if deferral.String() != expected {
+		t.Errorf("The deferral is %s", deferral)
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// DeploymentPlan deploys a lump sum gradually between `Start` and `End` on top of the regular DCA, the `Allocation`
+// mapping each pair to its share of the total in percent
+type DeploymentPlan struct {
+	Name       string             `yaml:"name"`
+	Total      float64            `yaml:"total"`
+	Start      time.Time          `yaml:"start"`
+	End        time.Time          `yaml:"end"`
+	Allocation map[string]float64 `yaml:"allocation"`
+}
+
+// Active Check whether the plan deploys during the round happening at `now`, the rounds after `End` catching up what
+// is left to deploy
+func (d DeploymentPlan) Active(now time.Time) bool {
+	return !now.Before(d.Start)
+}
+
+// RoundsLeft Get the number of rounds left before the end of the plan, the round happening at `now` included. The rounds
+// happening after the end of the plan are the last ones.
+func (d DeploymentPlan) RoundsLeft(now time.Time, period time.Duration) int {
+	if period <= 0 || now.After(d.End) {
+		return 1
+	}
+
+	return int(math.Floor(float64(d.End.Sub(now))/float64(period))) + 1
+}
+
+// Slice Get the amount to deploy during the round happening at `now` given the amount already `deployed`.
+// The remaining amount is spread evenly over the rounds left, so that failed rounds are caught up and the whole
+// remainder is deployed by the last round, or by the next rounds after `End` until the total is deployed.
+func (d DeploymentPlan) Slice(deployed float64, now time.Time, period time.Duration) float64 {
+	remaining := d.Total - deployed
+	if !d.Active(now) || remaining <= 0 {
+		return 0
+	}
+
+	return remaining / float64(d.RoundsLeft(now, period))
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeploymentPlanSlice(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	plan := DeploymentPlan{
+		Name:  "bonus",
+		Total: 6000,
+		Start: start,
+		End:   start.Add(11 * week),
+	}
+
+	cases := []struct {
+		deployed float64
+		now      time.Time
+		slice    float64
+	}{
+		{0, start.Add(-week), 0},
+		{0, start, 500},
+		{500, start.Add(week), 500},
+		{500, start.Add(2 * week), 550},
+		{5000, start.Add(11 * week), 1000},
+		{6000, start.Add(11 * week), 0},
+		{5000, start.Add(11*week + 3*24*time.Hour), 1000},
+		{5500, start.Add(12 * week), 500},
+		{6000, start.Add(12 * week), 0},
+	}
+
+	for _, c := range cases {
+		slice := plan.Slice(c.deployed, c.now, week)
+		if slice != c.slice {
+			t.Errorf("The slice at %v with %f deployed is %f instead of %f", c.now, c.deployed, slice, c.slice)
+		}
+	}
+}
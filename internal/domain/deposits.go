@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"github.com/xhit/go-str2duration/v2"
+	"time"
+)
+
+// Deposits triggers an investment round when a fiat deposit lands on the account, the deposited amount being split
+// between pairs according to `Allocation`, in percent
+type Deposits struct {
+	// Interval is the duration between two polls of the account ledger
+	Interval   string             `yaml:"interval"`
+	Allocation map[string]float64 `yaml:"allocation"`
+}
+
+// Split Get the amount to invest in each pair for the given deposit amount
+func (d Deposits) Split(amount float64) map[string]float64 {
+	amounts := map[string]float64{}
+	for pair, percentage := range d.Allocation {
+		amounts[pair] = amount * percentage / 100
+	}
+
+	return amounts
+}
+
+// PollInterval Get the duration between two polls of the account ledger
+func (d Deposits) PollInterval() (time.Duration, error) {
+	return str2duration.ParseDuration(d.Interval)
+}
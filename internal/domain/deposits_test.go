@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDepositsSplit(t *testing.T) {
+	deposits := Deposits{Allocation: map[string]float64{"XXBTZEUR": 60, "XETHZEUR": 40}}
+
+	amounts := deposits.Split(500)
+	if !reflect.DeepEqual(amounts, map[string]float64{"XXBTZEUR": 300, "XETHZEUR": 200}) {
+		t.Errorf("The deposit split is %v", amounts)
+	}
+
+	ledger := Ledger{Amount: 500, Fee: 0.5}
+	if ledger.Net() != 499.5 {
+		t.Errorf("The ledger net amount is %f instead of 499.5", ledger.Net())
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Drawdown describes the multiplier tiers applied to a pair amount depending on how far the price is from its
+// `Days` high
+type Drawdown struct {
+	Days  int            `yaml:"days"`
+	Tiers []DrawdownTier `yaml:"tiers"`
+}
+
+// DrawdownTier applies `Multiplier` when the price is at least `Drawdown` percent below its high
+type DrawdownTier struct {
+	Drawdown   float64 `yaml:"drawdown"`
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// Multiplier Get the multiplier of the tier matching the drawdown of `price` from `high`, along with its reason.
+// A 1x multiplier is returned when no tier matches.
+func (d Drawdown) Multiplier(high float64, price float64) (float64, string) {
+	if high <= 0 {
+		return 1, ""
+	}
+
+	drawdown := (high - price) / high * 100
+	tiers := make([]DrawdownTier, len(d.Tiers))
+	copy(tiers, d.Tiers)
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Drawdown > tiers[j].Drawdown
+	})
+
+	for _, tier := range tiers {
+		if drawdown >= tier.Drawdown {
+			return tier.Multiplier, fmt.Sprintf("%.1f%% drawdown from the %d-day high of %.2f (-%.0f%% tier)", drawdown, d.Days, high, tier.Drawdown)
+		}
+	}
+
+	return 1, ""
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "testing"
+
+func TestDrawdownMultiplier(t *testing.T) {
+	drawdown := Drawdown{
+		Days: 90,
+		Tiers: []DrawdownTier{
+			{Drawdown: 40, Multiplier: 2},
+			{Drawdown: 20, Multiplier: 1.5},
+		},
+	}
+
+	cases := []struct {
+		high       float64
+		price      float64
+		multiplier float64
+		reason     string
+	}{
+		{100, 95, 1, ""},
+		{100, 80, 1.5, "20.0% drawdown from the 90-day high of 100.00 (-20% tier)"},
+		{100, 45, 2, "55.0% drawdown from the 90-day high of 100.00 (-40% tier)"},
+		{0, 45, 1, ""},
+	}
+
+	for _, c := range cases {
+		multiplier, reason := drawdown.Multiplier(c.high, c.price)
+		if multiplier != c.multiplier || reason != c.reason {
+			t.Errorf("The %f/%f multiplier is %f (%s) instead of %f (%s)", c.price, c.high, multiplier, reason, c.multiplier, c.reason)
+		}
+	}
+}
+
+func TestHighest(t *testing.T) {
+	candles := []Candle{{High: 12}, {High: 18.5}, {High: 3}}
+
+	if Highest(candles) != 18.5 {
+		t.Errorf("The highest price is %f instead of 18.5", Highest(candles))
+	}
+}
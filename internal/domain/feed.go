@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"github.com/xhit/go-str2duration/v2"
+	"time"
+)
+
+// Feed defaults
+const (
+	DefaultFeedUrl       = "wss://ws.kraken.com"
+	DefaultFeedStaleness = "10s"
+)
+
+// Feed streams the prices of the traded pairs from the Kraken WebSocket API at `Url`, instead of requesting the REST
+// ticker before every order. The streamed prices are stale, and the REST ticker used, when no message was received for
+// `Staleness`.
+type Feed struct {
+	Url       string `yaml:"url"`
+	Staleness string `yaml:"staleness"`
+}
+
+// StalenessDuration Get the duration without any message after which the streamed prices are stale
+func (f Feed) StalenessDuration() (time.Duration, error) {
+	return str2duration.ParseDuration(f.Staleness)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Goal describes an accumulation target : hold `Quantity` of the pair base asset by `Deadline`, each round spending
+// between `Min` and `Max`
+type Goal struct {
+	Quantity float64   `yaml:"quantity"`
+	Deadline time.Time `yaml:"deadline"`
+	Min      float64   `yaml:"min"`
+	Max      float64   `yaml:"max"`
+}
+
+// GoalProgress is the progress of a pair toward its accumulation goal
+type GoalProgress struct {
+	Held     float64
+	Target   float64
+	Deadline time.Time
+}
+
+// Percentage Get the percentage of the goal quantity already held
+func (p GoalProgress) Percentage() float64 {
+	if p.Target <= 0 {
+		return 100
+	}
+
+	return math.Min(p.Held/p.Target*100, 100)
+}
+
+// RoundsLeft Get the number of rounds left before the deadline, the round happening at `now` included
+func (g Goal) RoundsLeft(now time.Time, period time.Duration) int {
+	if period <= 0 || !now.Before(g.Deadline) {
+		return 1
+	}
+
+	return int(math.Floor(float64(g.Deadline.Sub(now))/float64(period))) + 1
+}
+
+// Amount Get the amount to spend at `price` during the round happening at `now` for the remaining quantity to be
+// bought evenly over the rounds left, bounded by `Min` and `Max`. Nothing is spent once the goal is reached.
+func (g Goal) Amount(held float64, price float64, now time.Time, period time.Duration) float64 {
+	remaining := g.Quantity - held
+	if remaining <= 0 {
+		return 0
+	}
+
+	amount := math.Max(remaining/float64(g.RoundsLeft(now, period))*price, g.Min)
+	if g.Max > 0 {
+		amount = math.Min(amount, g.Max)
+	}
+
+	return amount
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGoalAmount(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	goal := Goal{
+		Quantity: 0.5,
+		Deadline: now.Add(9*week + time.Hour),
+		Min:      10,
+		Max:      2000,
+	}
+
+	cases := []struct {
+		held   float64
+		price  float64
+		now    time.Time
+		amount float64
+	}{
+		{0, 20000, now, 1000},
+		{0.45, 20000, now, 100},
+		{0.4999, 20000, now, 10},
+		{0.5, 20000, now, 0},
+		{0, 20000, now.Add(20 * week), 2000},
+	}
+
+	for _, c := range cases {
+		amount := goal.Amount(c.held, c.price, c.now, week)
+		if math.Abs(amount-c.amount) > 1e-9 {
+			t.Errorf("The amount with %f held at %f is %f instead of %f", c.held, c.price, amount, c.amount)
+		}
+	}
+}
+
+func TestGoalProgressPercentage(t *testing.T) {
+	progress := GoalProgress{Held: 0.1, Target: 0.5}
+
+	if progress.Percentage() != 20 {
+		t.Errorf("The goal progress is %f%% instead of 20%%", progress.Percentage())
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "math"
+
+// Indicators describes how technical indicators scale a pair amount.
+// Every configured indicator multiplies the amount, the result being bounded by `Min` and `Max`.
+type Indicators struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+
+	// MovingAverage maps the distance of the price from its moving average, in percent, to multipliers
+	MovingAverage *IndicatorRule `yaml:"movingAverage"`
+	// RSI maps the relative strength index to multipliers
+	RSI *IndicatorRule `yaml:"rsi"`
+	// Volatility maps the average true range, in percent of the price, to multipliers
+	Volatility *IndicatorRule `yaml:"volatility"`
+}
+
+// IndicatorRule maps the value of an indicator computed over `Period` daily candles to multipliers
+type IndicatorRule struct {
+	Period int              `yaml:"period"`
+	Ranges []IndicatorRange `yaml:"ranges"`
+}
+
+// IndicatorRange applies `Multiplier` when the indicator value is in the [From, To) range
+type IndicatorRange struct {
+	From       float64 `yaml:"from"`
+	To         float64 `yaml:"to"`
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// Multiplier Get the multiplier of the first range containing the value, 1 if none matches
+func (r IndicatorRule) Multiplier(value float64) float64 {
+	for _, indicatorRange := range r.Ranges {
+		if value >= indicatorRange.From && value < indicatorRange.To {
+			return indicatorRange.Multiplier
+		}
+	}
+
+	return 1
+}
+
+// Bound Get the amount bounded by the configured minimum and maximum
+func (i Indicators) Bound(amount float64) float64 {
+	amount = math.Max(amount, i.Min)
+	if i.Max > 0 {
+		amount = math.Min(amount, i.Max)
+	}
+
+	return amount
+}
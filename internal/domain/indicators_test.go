@@ -0,0 +1,48 @@
+package domain
+
+import "testing"
+
+func TestIndicatorRuleMultiplier(t *testing.T) {
+	rule := IndicatorRule{
+		Period: 14,
+		Ranges: []IndicatorRange{
+			{From: 0, To: 30, Multiplier: 1.5},
+			{From: 70, To: 100, Multiplier: 0.5},
+		},
+	}
+
+	cases := []struct {
+		value      float64
+		multiplier float64
+	}{
+		{12, 1.5},
+		{30, 1},
+		{50, 1},
+		{85, 0.5},
+	}
+
+	for _, c := range cases {
+		if rule.Multiplier(c.value) != c.multiplier {
+			t.Errorf("The %f multiplier is %f instead of %f", c.value, rule.Multiplier(c.value), c.multiplier)
+		}
+	}
+}
+
+func TestIndicatorsBound(t *testing.T) {
+	indicators := Indicators{Min: 5, Max: 50}
+
+	cases := []struct {
+		amount  float64
+		bounded float64
+	}{
+		{2, 5},
+		{20, 20},
+		{80, 50},
+	}
+
+	for _, c := range cases {
+		if indicators.Bound(c.amount) != c.bounded {
+			t.Errorf("The %f bounded amount is %f instead of %f", c.amount, indicators.Bound(c.amount), c.bounded)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Ledger is an entry of the Kraken account ledger
+type Ledger struct {
+	Id     string
+	Time   time.Time
+	Type   string
+	Asset  string
+	Amount float64
+	Fee    float64
+}
+
+// Net Get the amount credited to the account, fees deducted
+func (l Ledger) Net() float64 {
+	return l.Amount - l.Fee
+}
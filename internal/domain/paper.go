@@ -0,0 +1,12 @@
+package domain
+
+// DefaultPaperFee is the Kraken taker fee percentage of the lowest volume tier
+const DefaultPaperFee = 0.26
+
+// Paper simulates the trading on a virtual portfolio persisted in the `Portfolio` file, the prices being the exchange
+// ones. The portfolio starts with the `Balances` quantities and every order is charged the `Fee` percentage.
+type Paper struct {
+	Portfolio string             `yaml:"portfolio"`
+	Fee       float64            `yaml:"fee"`
+	Balances  map[string]float64 `yaml:"balances"`
+}
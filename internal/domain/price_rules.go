@@ -0,0 +1,34 @@
+package domain
+
+import "fmt"
+
+// PriceRules reduces or skips a purchase when the price is above `Ceiling` and buys extra when it is below `Floor`.
+// A zero ceiling or floor is not enforced.
+type PriceRules struct {
+	Ceiling float64 `yaml:"ceiling"`
+	// CeilingMultiplier is applied to the amount above the ceiling, the purchase being skipped when it is 0
+	CeilingMultiplier float64 `yaml:"ceilingMultiplier"`
+	Floor             float64 `yaml:"floor"`
+	FloorMultiplier   float64 `yaml:"floorMultiplier"`
+	// Rollover adds the amount left unspent because of the ceiling to the next round happening below it.
+	// The unspent amount is dropped otherwise.
+	Rollover bool `yaml:"rollover"`
+}
+
+// AboveCeiling Check whether the price is above the configured ceiling
+func (r PriceRules) AboveCeiling(price float64) bool {
+	return r.Ceiling > 0 && price > r.Ceiling
+}
+
+// Multiplier Get the multiplier to apply to the amount at the given price along with its reason, 1 if no rule applies
+func (r PriceRules) Multiplier(price float64) (float64, string) {
+	if r.AboveCeiling(price) {
+		return r.CeilingMultiplier, fmt.Sprintf("price %.2f above the %.2f ceiling", price, r.Ceiling)
+	}
+
+	if r.Floor > 0 && price < r.Floor && r.FloorMultiplier > 0 {
+		return r.FloorMultiplier, fmt.Sprintf("price %.2f below the %.2f floor", price, r.Floor)
+	}
+
+	return 1, ""
+}
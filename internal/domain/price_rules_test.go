@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestPriceRulesMultiplier(t *testing.T) {
+	rules := PriceRules{Ceiling: 80000, CeilingMultiplier: 0.5, Floor: 30000, FloorMultiplier: 2}
+
+	cases := []struct {
+		price        float64
+		multiplier   float64
+		reason       string
+		aboveCeiling bool
+	}{
+		{85000, 0.5, "price 85000.00 above the 80000.00 ceiling", true},
+		{50000, 1, "", false},
+		{25000, 2, "price 25000.00 below the 30000.00 floor", false},
+	}
+
+	for _, c := range cases {
+		multiplier, reason := rules.Multiplier(c.price)
+		if multiplier != c.multiplier || reason != c.reason || rules.AboveCeiling(c.price) != c.aboveCeiling {
+			t.Errorf("The %f multiplier is %f (%s)", c.price, multiplier, reason)
+		}
+	}
+
+	if multiplier, _ := (PriceRules{}).Multiplier(100); multiplier != 1 {
+		t.Errorf("The multiplier without rules is %f", multiplier)
+	}
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// year is the duration the money-weighted return is annualized over
+const year = 365 * 24 * time.Hour
+
+// CashFlow is an amount invested, negative, or received, positive, at a date
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// ErrNoReturn is returned when the cash flows have no return, e.g. when nothing was invested
+var ErrNoReturn = errors.New("the cash flows have no return")
+
+// XIRR Get the money-weighted return of the cash flows, i.e. the annual rate for which their net present value is zero.
+// The current value of the investment is the last, positive, cash flow.
+func XIRR(flows []CashFlow) (float64, error) {
+	var invested, received bool
+	for _, flow := range flows {
+		invested = invested || flow.Amount < 0
+		received = received || flow.Amount > 0
+	}
+	if !invested || !received {
+		return 0, ErrNoReturn
+	}
+
+	sorted := append([]CashFlow{}, flows...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	start := sorted[0].Date
+
+	// The net present value decreases with the rate, whose root is found by bisection
+	npv := func(rate float64) float64 {
+		value := 0.0
+		for _, flow := range sorted {
+			years := float64(flow.Date.Sub(start)) / float64(year)
+			value += flow.Amount / math.Pow(1+rate, years)
+		}
+
+		return value
+	}
+
+	low, high := -0.999999, 1.0
+	for npv(high) > 0 {
+		high *= 2
+		if high > 1e9 {
+			return 0, ErrNoReturn
+		}
+	}
+	if npv(low) < 0 {
+		return 0, ErrNoReturn
+	}
+
+	for iteration := 0; iteration < 200 && high-low > 1e-12; iteration++ {
+		middle := (low + high) / 2
+		if npv(middle) > 0 {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return (low + high) / 2, nil
+}
+
+// Period is a sub-period of a time-weighted return : the value at its start, right after the previous cash flow, and
+// at its end, right before the next one
+type Period struct {
+	Start float64
+	End   float64
+}
+
+// TWR Get the time-weighted return of the periods, i.e. their chained returns, neutralizing the cash flows.
+// The periods starting with no value are ignored.
+func TWR(periods []Period) (float64, error) {
+	growth := 1.0
+	chained := false
+	for _, period := range periods {
+		if period.Start <= 0 {
+			continue
+		}
+
+		growth *= period.End / period.Start
+		chained = true
+	}
+
+	if !chained {
+		return 0, ErrNoReturn
+	}
+
+	return growth - 1, nil
+}
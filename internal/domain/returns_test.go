@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestXIRR(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rate, err := XIRR([]CashFlow{{start, -1000}, {start.Add(year), 1100}})
+	if err != nil || math.Abs(rate-0.1) > 1e-9 {
+		t.Errorf("The return is %f (%v)", rate, err)
+	}
+
+	flows := []CashFlow{{start, -100}, {start.Add(year / 2), -100}, {start.Add(year), 190}}
+	rate, err = XIRR(flows)
+	if err != nil || rate > 0 {
+		t.Fatalf("The return of a loss is %f (%v)", rate, err)
+	}
+	npv := -100 - 100/math.Pow(1+rate, 0.5) + 190/(1+rate)
+	if math.Abs(npv) > 1e-6 {
+		t.Errorf("The %f return net present value is %f", rate, npv)
+	}
+
+	_, err = XIRR([]CashFlow{{start, -1000}})
+	if err != ErrNoReturn {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestTWR(t *testing.T) {
+	twr, err := TWR([]Period{{0, 0}, {100, 110}, {210, 189}})
+	if err != nil || math.Abs(twr-(-0.01)) > 1e-9 {
+		t.Errorf("The return is %f (%v)", twr, err)
+	}
+
+	_, err = TWR([]Period{{0, 0}})
+	if err != ErrNoReturn {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SellPair gradually sells an asset every round, either `Amount` worth of the quote currency or a fixed `Quantity`
+// of the asset. Selling pauses while the price is below `Floor`, a zero floor being ignored.
+type SellPair struct {
+	Pair     string  `yaml:"pair"`
+	Amount   float64 `yaml:"amount"`
+	Quantity float64 `yaml:"quantity"`
+	Floor    float64 `yaml:"floor"`
+}
+
+// Validate Check that exactly one of the amount and the quantity is specified
+func (s SellPair) Validate() error {
+	if (s.Amount > 0) == (s.Quantity > 0) {
+		return fmt.Errorf("the %s sell pair must specify either an amount or a quantity", s.Pair)
+	}
+
+	if s.Floor < 0 {
+		return errors.New("the sell floor cannot be negative")
+	}
+
+	return nil
+}
+
+// Volume Get the quantity of the asset to sell at the given price
+func (s SellPair) Volume(price float64) float64 {
+	if s.Quantity > 0 {
+		return s.Quantity
+	}
+
+	return s.Amount / price
+}
+
+// BelowFloor Get whether selling must pause at the given price
+func (s SellPair) BelowFloor(price float64) bool {
+	return s.Floor > 0 && price < s.Floor
+}
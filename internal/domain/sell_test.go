@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestSellPairValidate(t *testing.T) {
+	cases := []struct {
+		sell  SellPair
+		valid bool
+	}{
+		{SellPair{Pair: "XXBTZEUR", Amount: 50}, true},
+		{SellPair{Pair: "XXBTZEUR", Quantity: 0.01, Floor: 30000}, true},
+		{SellPair{Pair: "XXBTZEUR"}, false},
+		{SellPair{Pair: "XXBTZEUR", Amount: 50, Quantity: 0.01}, false},
+		{SellPair{Pair: "XXBTZEUR", Amount: 50, Floor: -1}, false},
+	}
+
+	for _, c := range cases {
+		err := c.sell.Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("The %+v sell pair validation returned %v", c.sell, err)
+		}
+	}
+}
+
+func TestSellPairVolume(t *testing.T) {
+	amount := SellPair{Amount: 50}
+	if volume := amount.Volume(25000); volume != 0.002 {
+		t.Errorf("The amount volume is %v", volume)
+	}
+
+	quantity := SellPair{Quantity: 0.01}
+	if volume := quantity.Volume(25000); volume != 0.01 {
+		t.Errorf("The quantity volume is %v", volume)
+	}
+}
+
+func TestSellPairBelowFloor(t *testing.T) {
+	sell := SellPair{Quantity: 0.01, Floor: 30000}
+
+	if !sell.BelowFloor(29999) {
+		t.Errorf("The price should be below the floor")
+	}
+
+	if sell.BelowFloor(30000) {
+		t.Errorf("The price should not be below the floor")
+	}
+
+	if (SellPair{Quantity: 0.01}).BelowFloor(1) {
+		t.Errorf("A zero floor should be ignored")
+	}
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xhit/go-str2duration/v2"
+	"time"
+)
+
+// DefaultShadowReport is the default interval between two shadow comparison reports
+const DefaultShadowReport = "7d"
+
+// Shadows runs strategies on paper portfolios next to the live one, on the same schedule, and periodically reports how
+// they compare
+type Shadows struct {
+	// Report is the interval between two comparison reports
+	Report     string   `yaml:"report"`
+	Strategies []Shadow `yaml:"strategies"`
+}
+
+// Shadow is a strategy buying the `Pairs` and selling the `Sells` on the `Paper` portfolio, the other settings being
+// the live ones. Its virtual transactions are recorded in the `Storage` history and its values in the `State` file,
+// apart from the live ones.
+type Shadow struct {
+	Name    string     `yaml:"name"`
+	Paper   Paper      `yaml:"paper"`
+	Storage string     `yaml:"storage"`
+	State   string     `yaml:"state"`
+	Pairs   []DCAPair  `yaml:"pairs"`
+	Sells   []SellPair `yaml:"sells"`
+}
+
+// setDefaults Name the files of the strategy after it and charge the default paper fee when none is set
+func (s *Shadow) setDefaults() {
+	if s.Paper.Portfolio == "" {
+		s.Paper.Portfolio = "shadow-" + s.Name + "-portfolio.json"
+	}
+
+	if s.Paper.Fee == 0 {
+		s.Paper.Fee = DefaultPaperFee
+	}
+
+	if s.Storage == "" {
+		s.Storage = "shadow-" + s.Name + "-history.json"
+	}
+
+	if s.State == "" {
+		s.State = "shadow-" + s.Name + "-state.json"
+	}
+}
+
+// ReportInterval Get the duration between two comparison reports
+func (s Shadows) ReportInterval() (time.Duration, error) {
+	return str2duration.ParseDuration(s.Report)
+}
+
+// Validate Check that the strategies have unique names and that the report interval is valid
+func (s Shadows) Validate() error {
+	_, err := s.ReportInterval()
+	if err != nil {
+		return fmt.Errorf("the shadows report interval is invalid : %w", err)
+	}
+
+	names := map[string]bool{}
+	for _, shadow := range s.Strategies {
+		if shadow.Name == "" {
+			return errors.New("the shadow strategies must be named")
+		}
+
+		if names[shadow.Name] {
+			return fmt.Errorf("the %s shadow strategy is declared twice", shadow.Name)
+		}
+		names[shadow.Name] = true
+
+		for _, sell := range shadow.Sells {
+			err = sell.Validate()
+			if err != nil {
+				return fmt.Errorf("the %s shadow strategy is invalid : %w", shadow.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Config Get the configuration the shadow strategy invests with : the live one with the shadow pairs, sells and files.
+// The purchases are not staked and no withdrawal is made.
+func (s Shadow) Config(live Config) Config {
+	config := live
+	config.Paper = &s.Paper
+	config.Storage = s.Storage
+	config.State = s.State
+	config.Sells = s.Sells
+	config.Withdrawals = nil
+
+	config.Pairs = append([]DCAPair{}, s.Pairs...)
+	for index := range config.Pairs {
+		config.Pairs[index].Staking = nil
+	}
+
+	return config
+}
+
+// Performance is the outcome of a strategy transactions, the amounts being in quote currency
+type Performance struct {
+	Name string
+	// Invested is the amount spent by the purchases, fees included
+	Invested float64
+	// Proceeds is the amount received by the sales, fees deducted
+	Proceeds float64
+	// Value is the value of the volumes bought and not sold
+	Value float64
+}
+
+// NewPerformance Get the performance of the transactions, the volumes still held being valued at the pair `prices`.
+// The failed, skipped and stake transactions are ignored.
+func NewPerformance(name string, transactions []Transaction, prices map[string]float64) Performance {
+	performance := Performance{Name: name}
+	for _, transaction := range transactions {
+		if transaction.Exception != nil || transaction.SkipReason != "" || transaction.Id == "" || transaction.IsStake() {
+			continue
+		}
+
+		if transaction.IsSell() {
+			performance.Proceeds += transaction.Proceeds()
+			performance.Value -= transaction.Amount * prices[transaction.Pair]
+
+			continue
+		}
+
+		performance.Invested += transaction.Cost()
+		performance.Value += transaction.Amount * prices[transaction.Pair]
+	}
+
+	return performance
+}
+
+// Gain Get the value and proceeds of the strategy minus the invested amount
+func (p Performance) Gain() float64 {
+	return p.Value + p.Proceeds - p.Invested
+}
+
+// Return Get the gain in percent of the invested amount
+func (p Performance) Return() float64 {
+	if p.Invested == 0 {
+		return 0
+	}
+
+	return p.Gain() / p.Invested * 100
+}
+
+// Comparison is the performance of the live strategy, first, and of the shadow ones since the shadows started
+type Comparison struct {
+	Since        time.Time
+	Performances []Performance
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestShadowsValidate(t *testing.T) {
+	cases := []struct {
+		shadows Shadows
+		error   string
+	}{
+		{Shadows{Report: "7d", Strategies: []Shadow{{Name: "a"}, {Name: "b"}}}, ""},
+		{Shadows{Report: "weekly"}, "the shadows report interval is invalid : time: invalid duration \"weekly\""},
+		{Shadows{Report: "7d", Strategies: []Shadow{{}}}, "the shadow strategies must be named"},
+		{Shadows{Report: "7d", Strategies: []Shadow{{Name: "a"}, {Name: "a"}}}, "the a shadow strategy is declared twice"},
+	}
+
+	for _, c := range cases {
+		err := c.shadows.Validate()
+		if (err == nil && c.error != "") || (err != nil && err.Error() != c.error) {
+			t.Errorf("The %+v shadows validation returned %v", c.shadows, err)
+		}
+	}
+}
+
+func TestShadowConfig(t *testing.T) {
+	live := Config{
+		Currency:    "ZEUR",
+		Storage:     "history.json",
+		Pairs:       []DCAPair{{Pair: "XETHZEUR", Amount: 10}},
+		Withdrawals: &Withdrawals{},
+	}
+	shadow := Shadow{Name: "btc", Storage: "shadow.json", Pairs: []DCAPair{{Pair: "XXBTZEUR", Amount: 20, Staking: &Staking{}}}}
+
+	config := shadow.Config(live)
+	if config.Currency != "ZEUR" || config.Storage != "shadow.json" || config.Paper == nil || config.Withdrawals != nil {
+		t.Errorf("The shadow configuration is %+v", config)
+	}
+
+	if len(config.Pairs) != 1 || config.Pairs[0].Pair != "XXBTZEUR" || config.Pairs[0].Staking != nil || shadow.Pairs[0].Staking == nil {
+		t.Errorf("The shadow pairs are %+v", config.Pairs)
+	}
+}
+
+func TestNewPerformance(t *testing.T) {
+	transactions := []Transaction{
+		*NewTransaction("XXBTZEUR").Complete("TX1", 20000, 0.01, 0.0001),
+		*NewSellTransaction("XXBTZEUR").Complete("TX2", 25000, 0.005, 0.00005),
+		*NewStakeTransaction("XXBTZEUR").Complete("STAKE", 20000, 0.005, 0),
+		*NewTransaction("XETHZEUR").Fail(errors.New("order failed")),
+	}
+
+	performance := NewPerformance("live", transactions, map[string]float64{"XXBTZEUR": 30000})
+	if math.Abs(performance.Invested-202) > 1e-9 || math.Abs(performance.Proceeds-123.75) > 1e-9 || math.Abs(performance.Value-150) > 1e-9 {
+		t.Errorf("The performance is %+v", performance)
+	}
+
+	if math.Abs(performance.Return()-(150+123.75-202)/202*100) > 1e-9 {
+		t.Errorf("The return is %f", performance.Return())
+	}
+}
@@ -0,0 +1,8 @@
+package domain
+
+// Staking allocates the volume bought by every successful purchase to a Kraken Earn strategy.
+// The strategy defaults to the first one offered for the asset, the asset to the base asset of the pair.
+type Staking struct {
+	Strategy string `yaml:"strategy"`
+	Asset    string `yaml:"asset"`
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TakeProfit is a ladder of rungs selling part of the pair holdings as the price rises over the average cost basis.
+// Rungs at or below the cost basis are refused unless `BelowCost` is set.
+type TakeProfit struct {
+	Rungs     []Rung `yaml:"rungs"`
+	BelowCost bool   `yaml:"belowCost"`
+}
+
+// Rung sells `Percentage` of the holdings once the price is `Gain` percent over the average cost basis.
+// Each rung is executed once.
+type Rung struct {
+	Gain       float64 `yaml:"gain"`
+	Percentage float64 `yaml:"percentage"`
+}
+
+// Validate Check that the rungs sell a valid percentage of the holdings, above the cost basis unless allowed
+func (t TakeProfit) Validate() error {
+	for _, rung := range t.Rungs {
+		if rung.Percentage <= 0 || rung.Percentage > 100 {
+			return fmt.Errorf("the +%.2f%% take-profit rung must sell between 0 and 100%% of the holdings", rung.Gain)
+		}
+
+		if rung.Gain <= 0 && !t.BelowCost {
+			return fmt.Errorf("the %.2f%% take-profit rung sells below the cost basis, which must be explicitly allowed", rung.Gain)
+		}
+	}
+
+	return nil
+}
+
+// Triggered Get the rungs crossed by the gain over the cost basis, lowest first, leaving out the executed ones
+func (t TakeProfit) Triggered(gain float64, executed []float64) []Rung {
+	done := map[float64]bool{}
+	for _, executedGain := range executed {
+		done[executedGain] = true
+	}
+
+	var rungs []Rung
+	for _, rung := range t.Rungs {
+		if gain >= rung.Gain && !done[rung.Gain] {
+			rungs = append(rungs, rung)
+		}
+	}
+
+	sort.Slice(rungs, func(i, j int) bool {
+		return rungs[i].Gain < rungs[j].Gain
+	})
+
+	return rungs
+}
+
+// CostBasis Get the average price paid, fees included, for the pair base asset bought by the successful transactions,
+// false if none was bought
+func CostBasis(transactions []Transaction, pair string) (float64, bool) {
+	cost, quantity := 0.0, 0.0
+	for _, transaction := range transactions {
+		if transaction.Exception != nil || transaction.Id == "" || transaction.Id == StagedTransactionId ||
+			!transaction.IsBuy() || transaction.Pair != pair {
+			continue
+		}
+
+		cost += transaction.Cost()
+		quantity += transaction.Amount
+	}
+
+	if quantity == 0 {
+		return 0, false
+	}
+
+	return cost / quantity, true
+}
+
+// Gain Get the percentage the price is over the cost basis
+func Gain(price float64, costBasis float64) float64 {
+	return (price/costBasis - 1) * 100
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTakeProfitValidate(t *testing.T) {
+	cases := []struct {
+		takeProfit TakeProfit
+		valid      bool
+	}{
+		{TakeProfit{Rungs: []Rung{{Gain: 100, Percentage: 5}, {Gain: 200, Percentage: 5}}}, true},
+		{TakeProfit{Rungs: []Rung{{Gain: 100, Percentage: 0}}}, false},
+		{TakeProfit{Rungs: []Rung{{Gain: 100, Percentage: 150}}}, false},
+		{TakeProfit{Rungs: []Rung{{Gain: -10, Percentage: 5}}}, false},
+		{TakeProfit{Rungs: []Rung{{Gain: -10, Percentage: 5}}, BelowCost: true}, true},
+	}
+
+	for _, c := range cases {
+		err := c.takeProfit.Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("The %+v take-profit validation returned %v", c.takeProfit, err)
+		}
+	}
+}
+
+func TestTakeProfitTriggered(t *testing.T) {
+	takeProfit := TakeProfit{Rungs: []Rung{{Gain: 200, Percentage: 10}, {Gain: 100, Percentage: 5}, {Gain: 300, Percentage: 20}}}
+
+	rungs := takeProfit.Triggered(250, nil)
+	if !reflect.DeepEqual(rungs, []Rung{{Gain: 100, Percentage: 5}, {Gain: 200, Percentage: 10}}) {
+		t.Errorf("The triggered rungs are %v", rungs)
+	}
+
+	rungs = takeProfit.Triggered(250, []float64{100})
+	if !reflect.DeepEqual(rungs, []Rung{{Gain: 200, Percentage: 10}}) {
+		t.Errorf("The triggered rungs are %v", rungs)
+	}
+
+	if rungs = takeProfit.Triggered(50, nil); len(rungs) != 0 {
+		t.Errorf("No rung should be triggered : %v", rungs)
+	}
+}
+
+func TestCostBasis(t *testing.T) {
+	transactions := []Transaction{
+		{Id: "TX1", Pair: "XXBTZEUR", MarketPrice: 100, Amount: 0.9, Fee: 0.1},
+		{Id: "TX2", Pair: "XXBTZEUR", MarketPrice: 200, Amount: 1},
+		{Id: "TX3", Pair: "XXBTZEUR", Side: Sell, MarketPrice: 500, Amount: 1},
+		{Id: "TX4", Pair: "XETHZEUR", MarketPrice: 10, Amount: 1},
+		{Id: StagedTransactionId, Pair: "XXBTZEUR", MarketPrice: 1000, Amount: 1},
+	}
+
+	costBasis, ok := CostBasis(transactions, "XXBTZEUR")
+	if !ok || costBasis != 300/1.9 {
+		t.Errorf("The cost basis is %v", costBasis)
+	}
+
+	if _, ok = CostBasis(transactions, "XLTCZEUR"); ok {
+		t.Errorf("No cost basis should be found")
+	}
+
+	if gain := Gain(300, 100); gain != 200 {
+		t.Errorf("The gain is %v", gain)
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Trade is an execution of the public trade history of a pair
+type Trade struct {
+	Time   time.Time
+	Price  float64
+	Volume float64
+	// Side is the taker side, Buy or Sell
+	Side string
+}
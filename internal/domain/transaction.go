@@ -5,20 +5,51 @@ import (
 	"time"
 )
 
+// StagedTransactionId is the id of the transactions validated by Kraken without being executed
+const StagedTransactionId = "STAGED"
+
+// Transaction sides, transactions recorded before sells were supported having no side and being buys.
+// Stake transactions allocate a purchased volume to a Kraken Earn strategy, their id being the strategy id.
+const (
+	Buy   = "buy"
+	Sell  = "sell"
+	Stake = "stake"
+)
+
 type Transaction struct {
 	Id          string
 	Date        time.Time
 	Pair        string
+	Side        string
 	MarketPrice float64
 	Amount      float64
 	Fee         float64
-	Exception   error
+	Exception   error `json:"-"`
+	SkipReason  string
+
+	Multiplier       float64
+	MultiplierReason string
+
+	Goal *GoalProgress
+	// Budgeted is the part of a buy amount counted toward the monthly budget
+	Budgeted float64
+	// Rung is the take-profit rung executed by a sell transaction
+	Rung *Rung
 }
 
 func NewTransaction(pair string) *Transaction {
 	t := &Transaction{}
 	t.Date = time.Now()
 	t.Pair = pair
+	t.Side = Buy
+
+	return t
+}
+
+// NewSellTransaction Get a transaction selling the base asset of the pair
+func NewSellTransaction(pair string) *Transaction {
+	t := NewTransaction(pair)
+	t.Side = Sell
 
 	return t
 }
@@ -38,6 +69,85 @@ func (t *Transaction) Fail(exception error) *Transaction {
 	return t
 }
 
+// NewStakeTransaction Get a transaction staking the base asset of the pair
+func NewStakeTransaction(pair string) *Transaction {
+	t := NewTransaction(pair)
+	t.Side = Stake
+
+	return t
+}
+
+// IsBuy Get whether the transaction bought the base asset of the pair
+func (t *Transaction) IsBuy() bool {
+	return t.Side == Buy || t.Side == ""
+}
+
+// IsStake Get whether the transaction staked the base asset of the pair
+func (t *Transaction) IsStake() bool {
+	return t.Side == Stake
+}
+
+// IsSell Get whether the transaction sold the base asset of the pair
+func (t *Transaction) IsSell() bool {
+	return t.Side == Sell
+}
+
+// Cost Get the amount spent by the transaction, fees included, in the pair quote currency
+func (t *Transaction) Cost() float64 {
+	return (t.Amount + t.Fee) * t.MarketPrice
+}
+
+// Proceeds Get the amount received by a sell transaction, fees deducted, in the pair quote currency
+func (t *Transaction) Proceeds() float64 {
+	return (t.Amount - t.Fee) * t.MarketPrice
+}
+
+// Adjust Record a multiplier applied to the pair amount and the reason why it was applied.
+// Successive adjustments are combined : multipliers are multiplied and reasons joined.
+func (t *Transaction) Adjust(multiplier float64, reason string) *Transaction {
+	if t.Multiplier == 0 {
+		t.Multiplier = 1
+	}
+	t.Multiplier *= multiplier
+
+	if reason != "" && t.MultiplierReason != "" {
+		t.MultiplierReason += ", "
+	}
+	t.MultiplierReason += reason
+
+	return t
+}
+
+// Skip Mark the transaction as voluntarily not executed for the given reason
+func (t *Transaction) Skip(reason string) *Transaction {
+	t.SkipReason = reason
+
+	return t
+}
+
 func (t *Transaction) String() string {
-	return fmt.Sprintf("[%s][%s] %f at %f with %f fee", t.Id, t.Pair, t.Amount, t.MarketPrice, t.Fee)
+	if t.SkipReason != "" {
+		return fmt.Sprintf("[%s] skipped : %s", t.Pair, t.SkipReason)
+	}
+
+	description := fmt.Sprintf("[%s][%s] %f at %f with %f fee", t.Id, t.Pair, t.Amount, t.MarketPrice, t.Fee)
+	switch {
+	case t.IsSell():
+		description = fmt.Sprintf("[%s][%s] sold %f at %f with %f fee", t.Id, t.Pair, t.Amount, t.MarketPrice, t.Fee)
+	case t.IsStake():
+		description = fmt.Sprintf("[%s][%s] staked %f", t.Id, t.Pair, t.Amount)
+	}
+	if t.MultiplierReason != "" {
+		description += fmt.Sprintf(" (%.2fx : %s)", t.Multiplier, t.MultiplierReason)
+	}
+
+	if t.Goal != nil {
+		description += fmt.Sprintf(" [goal %.1f%% of %f]", t.Goal.Percentage(), t.Goal.Target)
+	}
+
+	if t.Rung != nil {
+		description += fmt.Sprintf(" [take-profit +%.0f%%]", t.Rung.Gain)
+	}
+
+	return description
 }
@@ -36,3 +36,60 @@ func TestTransactionString(t *testing.T) {
 		t.Errorf("Transaction string isn't correct %v", transaction.String())
 	}
 }
+
+func TestTransactionSkip(t *testing.T) {
+	transaction := NewTransaction("XETHZEUR")
+	transaction.Skip("test reason")
+
+	if transaction.SkipReason != "test reason" || transaction.Exception != nil {
+		t.Errorf("Transaction values aren't correct %v", transaction)
+	}
+
+	if transaction.String() != "[XETHZEUR] skipped : test reason" {
+		t.Errorf("Transaction string isn't correct %v", transaction.String())
+	}
+}
+
+func TestTransactionAdjust(t *testing.T) {
+	transaction := NewTransaction("XETHZEUR")
+	transaction.Adjust(1.5, "test reason")
+	transaction.Complete("TXID", 123.45, 543.21, 0.123)
+
+	if transaction.Multiplier != 1.5 || transaction.MultiplierReason != "test reason" {
+		t.Errorf("Transaction values aren't correct %v", transaction)
+	}
+
+	if transaction.String() != "[TXID][XETHZEUR] 543.210000 at 123.450000 with 0.123000 fee (1.50x : test reason)" {
+		t.Errorf("Transaction string isn't correct %v", transaction.String())
+	}
+}
+
+func TestTransactionSell(t *testing.T) {
+	transaction := NewSellTransaction("XETHZEUR")
+	transaction.Complete("TXID", 100, 2, 0.5)
+
+	if !transaction.IsSell() || NewTransaction("XETHZEUR").IsSell() {
+		t.Errorf("Transaction side isn't correct %v", transaction.Side)
+	}
+
+	if transaction.Proceeds() != 150 {
+		t.Errorf("Transaction proceeds aren't correct %v", transaction.Proceeds())
+	}
+
+	if transaction.String() != "[TXID][XETHZEUR] sold 2.000000 at 100.000000 with 0.500000 fee" {
+		t.Errorf("Transaction string isn't correct %v", transaction.String())
+	}
+}
+
+func TestTransactionStake(t *testing.T) {
+	transaction := NewStakeTransaction("XETHZEUR")
+	transaction.Complete("ETH-FLEX", 100, 2, 0)
+
+	if !transaction.IsStake() || transaction.IsBuy() || transaction.IsSell() {
+		t.Errorf("Transaction side isn't correct %v", transaction.Side)
+	}
+
+	if transaction.String() != "[ETH-FLEX][XETHZEUR] staked 2.000000" {
+		t.Errorf("Transaction string isn't correct %v", transaction.String())
+	}
+}
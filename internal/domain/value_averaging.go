@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// ValueAveraging describes a value averaging strategy : the pair holdings value should follow a path growing by
+// `Increment` every period since `Start`, the investor buying the difference between the target and the current value.
+type ValueAveraging struct {
+	Start     time.Time `yaml:"start"`
+	Increment float64   `yaml:"increment"`
+	Min       float64   `yaml:"min"`
+	Max       float64   `yaml:"max"`
+}
+
+// Target Get the target holdings value for the round happening at `now`
+func (v ValueAveraging) Target(now time.Time, period time.Duration) float64 {
+	if now.Before(v.Start) || period <= 0 {
+		return v.Increment
+	}
+
+	rounds := math.Floor(float64(now.Sub(v.Start)) / float64(period))
+
+	return v.Increment * (rounds + 1)
+}
+
+// Amount Get the amount to invest to reach the `target` value from the `current` value, bounded by `Min` and `Max`
+func (v ValueAveraging) Amount(target float64, current float64) float64 {
+	amount := math.Max(target-current, v.Min)
+	if v.Max > 0 {
+		amount = math.Min(amount, v.Max)
+	}
+
+	return amount
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+var valueAveraging = ValueAveraging{
+	Start:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	Increment: 50,
+	Min:       5,
+	Max:       120,
+}
+
+func TestValueAveragingTarget(t *testing.T) {
+	cases := []struct {
+		now    time.Time
+		target float64
+	}{
+		{time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), 50},
+		{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), 50},
+		{time.Date(2022, 1, 7, 23, 0, 0, 0, time.UTC), 50},
+		{time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC), 100},
+		{time.Date(2022, 1, 30, 0, 0, 0, 0, time.UTC), 250},
+	}
+
+	for _, c := range cases {
+		target := valueAveraging.Target(c.now, 7*24*time.Hour)
+		if target != c.target {
+			t.Errorf("The target at %v is %f instead of %f", c.now, target, c.target)
+		}
+	}
+}
+
+func TestValueAveragingAmount(t *testing.T) {
+	cases := []struct {
+		target  float64
+		current float64
+		amount  float64
+	}{
+		{250, 180, 70},
+		{250, 248, 5},
+		{250, 300, 5},
+		{250, 20, 120},
+	}
+
+	for _, c := range cases {
+		amount := valueAveraging.Amount(c.target, c.current)
+		if amount != c.amount {
+			t.Errorf("The amount for %f/%f is %f instead of %f", c.current, c.target, amount, c.amount)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "errors"
+
+// Webhook configures the HTTP listener accepting signed buy requests from external systems
+type Webhook struct {
+	// Address is the address the listener binds to, e.g. ":8080"
+	Address string `yaml:"address"`
+	// Secret is the key of the HMAC-SHA256 signature of the request bodies
+	Secret string `yaml:"secret"`
+	// Pairs is the allowlist of the pairs that can be bought through the webhook
+	Pairs []WebhookPair `yaml:"pairs"`
+}
+
+// WebhookPair allows buying `Pair` through the webhook, `Max` being the maximum amount of a single request
+type WebhookPair struct {
+	Pair string  `yaml:"pair"`
+	Max  float64 `yaml:"max"`
+}
+
+// Allowed Get the webhook allowlist entry of the pair, false if the pair isn't allowed
+func (w Webhook) Allowed(pair string) (WebhookPair, bool) {
+	for _, allowed := range w.Pairs {
+		if allowed.Pair == pair {
+			return allowed, true
+		}
+	}
+
+	return WebhookPair{}, false
+}
+
+// Validate Check that the listener address and the signature secret are set, every request being rejected otherwise
+func (w Webhook) Validate() error {
+	if w.Address == "" {
+		return errors.New("the webhook address is not specified")
+	}
+
+	if w.Secret == "" {
+		return errors.New("the webhook secret is not specified")
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xhit/go-str2duration/v2"
+	"time"
+)
+
+// Withdrawals sweeps holdings to the Kraken withdrawal keys (e.g. hardware wallet addresses) after each round.
+// Only the keys of the `Keys` allowlist can be withdrawn to, and no withdrawal is made in dry-run mode.
+type Withdrawals struct {
+	DryRun   bool               `yaml:"dryRun"`
+	Keys     []string           `yaml:"keys"`
+	Policies []WithdrawalPolicy `yaml:"policies"`
+}
+
+// WithdrawalPolicy withdraws the `Asset` holdings to the `Key` withdrawal key once they reach `Threshold`, leaving
+// `Leave` on the account, at most once every `Interval`
+type WithdrawalPolicy struct {
+	Asset     string  `yaml:"asset"`
+	Key       string  `yaml:"key"`
+	Threshold float64 `yaml:"threshold"`
+	Leave     float64 `yaml:"leave"`
+	Interval  string  `yaml:"interval"`
+}
+
+// Validate Check that the policies withdraw to allowlisted keys, with a threshold above the amount left behind
+func (w Withdrawals) Validate() error {
+	if len(w.Policies) > 0 && len(w.Keys) == 0 {
+		return errors.New("the withdrawal keys allowlist is mandatory")
+	}
+
+	allowed := map[string]bool{}
+	for _, key := range w.Keys {
+		allowed[key] = true
+	}
+
+	for _, policy := range w.Policies {
+		if !allowed[policy.Key] {
+			return fmt.Errorf("the %s withdrawal key is not in the allowlist", policy.Key)
+		}
+
+		if policy.Threshold <= policy.Leave || policy.Leave < 0 {
+			return fmt.Errorf("the %s withdrawal threshold must be greater than the amount left behind", policy.Asset)
+		}
+
+		if _, err := policy.MinInterval(); err != nil {
+			return fmt.Errorf("the %s withdrawal interval cannot be parsed : %w", policy.Asset, err)
+		}
+	}
+
+	return nil
+}
+
+// MinInterval Get the minimum duration between two withdrawals, zero if not limited
+func (p WithdrawalPolicy) MinInterval() (time.Duration, error) {
+	if p.Interval == "" {
+		return 0, nil
+	}
+
+	return str2duration.ParseDuration(p.Interval)
+}
+
+// Amount Get the amount to withdraw from the holdings, false if they are below the threshold
+func (p WithdrawalPolicy) Amount(holdings float64) (float64, bool) {
+	if holdings < p.Threshold {
+		return 0, false
+	}
+
+	return holdings - p.Leave, true
+}
+
+// Withdrawal is an attempt to withdraw an asset to a withdrawal key
+type Withdrawal struct {
+	// RefId is the Kraken reference of the withdrawal, empty in dry-run mode
+	RefId     string
+	Date      time.Time
+	Asset     string
+	Key       string
+	Amount    float64
+	Fee       float64
+	DryRun    bool
+	Exception error `json:"-"`
+}
+
+func NewWithdrawal(policy WithdrawalPolicy, amount float64, dryRun bool) *Withdrawal {
+	return &Withdrawal{
+		Date:   time.Now(),
+		Asset:  policy.Asset,
+		Key:    policy.Key,
+		Amount: amount,
+		DryRun: dryRun,
+	}
+}
+
+func (w *Withdrawal) Fail(exception error) *Withdrawal {
+	w.Exception = exception
+
+	return w
+}
+
+func (w *Withdrawal) String() string {
+	description := fmt.Sprintf("[%s][%s] %f withdrawn to %s with %f fee", w.RefId, w.Asset, w.Amount, w.Key, w.Fee)
+	if w.DryRun {
+		description = fmt.Sprintf("[dry-run][%s] %f would be withdrawn to %s with %f fee", w.Asset, w.Amount, w.Key, w.Fee)
+	}
+
+	if w.Exception != nil {
+		description += fmt.Sprintf(" failed : %v", w.Exception)
+	}
+
+	return description
+}
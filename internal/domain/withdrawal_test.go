@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithdrawalsValidate(t *testing.T) {
+	policy := WithdrawalPolicy{Asset: "XXBT", Key: "ledger", Threshold: 0.1, Leave: 0.01, Interval: "7d"}
+
+	cases := []struct {
+		withdrawals Withdrawals
+		valid       bool
+	}{
+		{Withdrawals{Keys: []string{"ledger"}, Policies: []WithdrawalPolicy{policy}}, true},
+		{Withdrawals{Policies: []WithdrawalPolicy{policy}}, false},
+		{Withdrawals{Keys: []string{"trezor"}, Policies: []WithdrawalPolicy{policy}}, false},
+		{Withdrawals{Keys: []string{"ledger"}, Policies: []WithdrawalPolicy{{Asset: "XXBT", Key: "ledger", Threshold: 0.1, Leave: 0.1}}}, false},
+		{Withdrawals{Keys: []string{"ledger"}, Policies: []WithdrawalPolicy{{Asset: "XXBT", Key: "ledger", Threshold: 0.1, Interval: "often"}}}, false},
+	}
+
+	for _, c := range cases {
+		err := c.withdrawals.Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("The %+v withdrawals validation returned %v", c.withdrawals, err)
+		}
+	}
+}
+
+func TestWithdrawalPolicyAmount(t *testing.T) {
+	policy := WithdrawalPolicy{Threshold: 0.1, Leave: 0.01, Interval: "7d"}
+
+	if _, ok := policy.Amount(0.09); ok {
+		t.Errorf("Holdings below the threshold should not be withdrawn")
+	}
+
+	if amount, ok := policy.Amount(0.5); !ok || amount != 0.49 {
+		t.Errorf("The withdrawal amount is %v", amount)
+	}
+
+	if interval, err := policy.MinInterval(); err != nil || interval != 7*24*time.Hour {
+		t.Errorf("The withdrawal interval is %v", interval)
+	}
+}
+
+func TestWithdrawalString(t *testing.T) {
+	withdrawal := NewWithdrawal(WithdrawalPolicy{Asset: "XXBT", Key: "ledger"}, 0.5, false)
+	withdrawal.RefId = "REF"
+	withdrawal.Fee = 0.0001
+
+	if withdrawal.String() != "[REF][XXBT] 0.500000 withdrawn to ledger with 0.000100 fee" {
+		t.Errorf("The withdrawal string is %v", withdrawal.String())
+	}
+
+	withdrawal.DryRun = true
+	withdrawal.Fail(errors.New("invalid key"))
+	if withdrawal.String() != "[dry-run][XXBT] 0.500000 would be withdrawn to ledger with 0.000100 fee failed : invalid key" {
+		t.Errorf("The withdrawal string is %v", withdrawal.String())
+	}
+}
@@ -0,0 +1,124 @@
+// Package exchange abstracts the exchanges the bot trades on. Every exchange is supported by an adapter implementing
+// Exchange, registered under the name selected by the `exchange` configuration field.
+package exchange
+
+//go:generate mockgen -destination=../mocks/mock_exchange.go -package=mocks . Exchange
+
+import (
+	"errors"
+	"kraken-dca-bot/internal/domain"
+	"time"
+)
+
+// ErrUnsupported is returned when an exchange doesn't support an optional feature
+var ErrUnsupported = errors.New("the feature is not supported by the exchange")
+
+// Exchange is the trading API of an exchange account, pairs and assets being named with the exchange codes. The
+// adapters holding connections also implement io.Closer.
+type Exchange interface {
+	// Balances Get the quantity held of every asset of the account
+	Balances() (map[string]float64, error)
+	// Ticker Get the best ask and bid prices of the pair
+	Ticker(pair string) (Ticker, error)
+	// Fee Get the taker fee percentage of the pair
+	Fee(pair string) (float64, error)
+	// Pair Get the trading rules of the pair
+	Pair(pair string) (Pair, error)
+	// Candles Get the OHLC history of the pair, `interval` being the candle duration in minutes
+	Candles(pair string, interval int) ([]domain.Candle, error)
+	// PlaceOrder Place the order and get its id, empty when the order is only validated
+	PlaceOrder(order Order) (string, error)
+	// QueryOrder Get the order with the given id
+	QueryOrder(id string) (Order, error)
+	// CancelOrder Cancel the open order with the given id
+	CancelOrder(id string) error
+}
+
+// Funding is implemented by the exchanges giving access to the account ledger and to withdrawals
+type Funding interface {
+	Ledgers(asset string, ledgerType string) ([]domain.Ledger, error)
+	WithdrawalFee(asset string, key string, amount float64) (float64, error)
+	Withdraw(asset string, key string, amount float64) (string, error)
+}
+
+// Earning is implemented by the exchanges offering staking products
+type Earning interface {
+	Stake(asset string, amount float64, strategy string) (string, error)
+}
+
+// MarketHistory is implemented by the exchanges serving the market history from a given time
+type MarketHistory interface {
+	// CandlesSince Get the OHLC candles of the pair started after `since`, the exchange capping their number
+	CandlesSince(pair string, interval int, since time.Time) ([]domain.Candle, error)
+	// Trades Get the trades of the pair following the `since` cursor, a Unix timestamp in nanoseconds or empty for the
+	// oldest trades, and the cursor of the next ones
+	Trades(pair string, since string) ([]domain.Trade, string, error)
+}
+
+// SystemStatus is implemented by the exchanges reporting whether their trading engine is available
+type SystemStatus interface {
+	// SystemStatus Get the trading status of the exchange
+	SystemStatus() (string, error)
+}
+
+// ServerTime is implemented by the exchanges serving their clock, to measure the drift of the local one
+type ServerTime interface {
+	// ServerTime Get the current time of the exchange
+	ServerTime() (time.Time, error)
+}
+
+// Trading statuses of the exchange and of the pairs, market orders being only accepted when online
+const (
+	Online      = "online"
+	Maintenance = "maintenance"
+	CancelOnly  = "cancel_only"
+	PostOnly    = "post_only"
+	LimitOnly   = "limit_only"
+	ReduceOnly  = "reduce_only"
+)
+
+// Ticker is the best ask and bid prices of a pair
+type Ticker struct {
+	Ask float64
+	Bid float64
+}
+
+// Pair is the trading rules of a pair
+type Pair struct {
+	Base  string
+	Quote string
+	// MinVolume is the minimum order volume, in base asset units
+	MinVolume float64
+	// VolumeDecimals is the precision of the order volumes
+	VolumeDecimals int
+	// Status is the trading status of the pair, online when empty
+	Status string
+}
+
+// Order sides and statuses
+const (
+	Buy  = "buy"
+	Sell = "sell"
+
+	Open     = "open"
+	Closed   = "closed"
+	Canceled = "canceled"
+)
+
+// Order is a market order of `Volume` base asset units
+type Order struct {
+	Id     string
+	Pair   string
+	Side   string
+	Volume float64
+	// Validate only checks the order without placing it
+	Validate bool
+	// Expiry is the duration after which an unfilled order is canceled, zero if it never expires
+	Expiry time.Duration
+
+	Status string
+	// Price is the average execution price of the filled volume
+	Price  float64
+	Filled float64
+	Fee    float64
+}
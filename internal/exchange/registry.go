@@ -0,0 +1,55 @@
+package exchange
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"sort"
+	"sync"
+)
+
+// Factory Get the adapter of an exchange, configured with the account credentials
+type Factory func(config domain.Config) (Exchange, error)
+
+var (
+	mutex     sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register Make the exchange adapter available under the given name. Adapters register themselves when their package
+// is initialized.
+func Register(name string, factory Factory) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("the %s exchange is registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// New Get the adapter of the exchange selected by the configuration
+func New(config domain.Config) (Exchange, error) {
+	mutex.RLock()
+	factory, ok := factories[config.Exchange]
+	mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("the %s exchange is not supported, the supported exchanges are %v", config.Exchange, Names())
+	}
+
+	return factory(config)
+}
+
+// Names Get the names of the registered exchanges, in alphabetical order
+func Names() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,32 @@
+package exchange
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"testing"
+)
+
+type testExchange struct {
+	Exchange
+	key string
+}
+
+func TestRegistry(t *testing.T) {
+	Register("test", func(config domain.Config) (Exchange, error) {
+		return testExchange{key: config.Kraken.Key}, nil
+	})
+	t.Cleanup(func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delete(factories, "test")
+	})
+
+	exchange, err := New(domain.Config{Exchange: "test", Kraken: domain.Kraken{Key: "key"}})
+	if err != nil || exchange.(testExchange).key != "key" {
+		t.Errorf("The test exchange is %v (%v)", exchange, err)
+	}
+
+	_, err = New(domain.Config{Exchange: "unknown"})
+	if err == nil || err.Error() != "the unknown exchange is not supported, the supported exchanges are [test]" {
+		t.Errorf("No relevant error was returned : %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package fake
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Strategy is an Earn strategy, allocating an asset to a staking product
+type Strategy struct {
+	Id    string
+	Asset string
+}
+
+func (s *Server) strategies(form url.Values) (interface{}, string) {
+	items := []map[string]interface{}{}
+	for _, strategy := range s.Strategies {
+		if asset := form.Get("asset"); asset == "" || asset == strategy.Asset {
+			items = append(items, map[string]interface{}{"id": strategy.Id, "asset": strategy.Asset})
+		}
+	}
+
+	return map[string]interface{}{"items": items}, ""
+}
+
+func (s *Server) allocate(form url.Values) (interface{}, string) {
+	amount, err := strconv.ParseFloat(form.Get("amount"), 64)
+	if err != nil || amount <= 0 {
+		return nil, "EGeneral:Invalid arguments:amount"
+	}
+
+	for _, strategy := range s.Strategies {
+		if strategy.Id == form.Get("strategy_id") {
+			s.Allocations[strategy.Id] += amount
+
+			return true, ""
+		}
+	}
+
+	return nil, "EEarnings:Invalid strategy"
+}
@@ -0,0 +1,204 @@
+package fake
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pair is a tradable pair, with its current prices and trading rules
+type Pair struct {
+	Base  string
+	Quote string
+	Ask   float64
+	Bid   float64
+	// Fee is the taker fee percentage
+	Fee         float64
+	LotDecimals int
+	OrderMin    float64
+	// Status is the pair trading status, online if empty
+	Status string
+	// WsName is the pair name on the WebSocket API (e.g. XBT/EUR), the pair name if empty
+	WsName string
+	// Candles are the pair OHLC history, served whatever the requested interval
+	Candles []domain.Candle
+	// Trades are the pair public trades, oldest first
+	Trades []domain.Trade
+}
+
+// maxCandles is the number of candles served by the OHLC method, the latest ones
+const maxCandles = 720
+
+// tradesPage is the number of trades served by a Trades request
+const tradesPage = 1000
+
+// SetPrices Update the pair prices, streaming them to the WebSocket subscribers, and fill the open limit orders they
+// cross
+func (s *Server) SetPrices(pair string, ask float64, bid float64) {
+	s.Lock()
+	defer s.Unlock()
+
+	previous := *s.Pairs[pair]
+	s.Pairs[pair].Ask = ask
+	s.Pairs[pair].Bid = bid
+	s.publish(pair, previous.Ask, previous.Bid)
+	s.matchOrders(pair)
+}
+
+func (s *Server) time(url.Values) (interface{}, string) {
+	now := s.Now()
+
+	return map[string]interface{}{
+		"unixtime": now.Unix(),
+		"rfc1123":  now.UTC().Format(time.RFC1123),
+	}, ""
+}
+
+func (s *Server) systemStatus(url.Values) (interface{}, string) {
+	return map[string]interface{}{
+		"status":    s.Status,
+		"timestamp": s.Now().UTC().Format(time.RFC3339),
+	}, ""
+}
+
+// requestedPairs Get the pairs of the comma separated `pair` parameter, all pairs if it's empty
+func (s *Server) requestedPairs(form url.Values) (map[string]*Pair, string) {
+	if form.Get("pair") == "" {
+		return s.Pairs, ""
+	}
+
+	pairs := map[string]*Pair{}
+	for _, name := range strings.Split(form.Get("pair"), ",") {
+		pair, ok := s.Pairs[name]
+		if !ok {
+			return nil, "EQuery:Unknown asset pair"
+		}
+		pairs[name] = pair
+	}
+
+	return pairs, ""
+}
+
+func (s *Server) assetPairs(form url.Values) (interface{}, string) {
+	pairs, message := s.requestedPairs(form)
+	if message != "" {
+		return nil, message
+	}
+
+	result := map[string]interface{}{}
+	for name, pair := range pairs {
+		status := pair.Status
+		if status == "" {
+			status = Online
+		}
+
+		result[name] = map[string]interface{}{
+			"altname":      name,
+			"base":         pair.Base,
+			"quote":        pair.Quote,
+			"lot_decimals": pair.LotDecimals,
+			"ordermin":     formatFloat(pair.OrderMin),
+			"status":       status,
+			"wsname":       wsName(name, pair),
+		}
+	}
+
+	return result, ""
+}
+
+func (s *Server) ticker(form url.Values) (interface{}, string) {
+	pairs, message := s.requestedPairs(form)
+	if message != "" {
+		return nil, message
+	}
+
+	result := map[string]interface{}{}
+	for name, pair := range pairs {
+		result[name] = map[string]interface{}{
+			"a": []string{formatFloat(pair.Ask), "1", "1.000"},
+			"b": []string{formatFloat(pair.Bid), "1", "1.000"},
+			"c": []string{formatFloat(pair.Bid), "0.1"},
+		}
+	}
+
+	return result, ""
+}
+
+// ohlc Serve the candles started after the `since` Unix timestamp, the latest 720 at most
+func (s *Server) ohlc(form url.Values) (interface{}, string) {
+	pair, ok := s.Pairs[form.Get("pair")]
+	if !ok {
+		return nil, "EQuery:Unknown asset pair"
+	}
+
+	since, _ := strconv.ParseInt(form.Get("since"), 10, 64)
+	entries := []interface{}{}
+	last := since
+	for _, candle := range pair.Candles {
+		if candle.Time.Unix() <= since {
+			continue
+		}
+
+		entries = append(entries, []interface{}{
+			candle.Time.Unix(),
+			formatFloat(candle.Open),
+			formatFloat(candle.High),
+			formatFloat(candle.Low),
+			formatFloat(candle.Close),
+			formatFloat(candle.Close),
+			formatFloat(candle.Volume),
+			1,
+		})
+		last = candle.Time.Unix()
+	}
+	if len(entries) > maxCandles {
+		entries = entries[len(entries)-maxCandles:]
+	}
+
+	return map[string]interface{}{
+		form.Get("pair"): entries,
+		"last":           last,
+	}, ""
+}
+
+// trades Serve a page of the trades executed after the `since` Unix timestamp in nanoseconds
+func (s *Server) trades(form url.Values) (interface{}, string) {
+	pair, ok := s.Pairs[form.Get("pair")]
+	if !ok {
+		return nil, "EQuery:Unknown asset pair"
+	}
+
+	since, _ := strconv.ParseInt(form.Get("since"), 10, 64)
+	entries := []interface{}{}
+	last := form.Get("since")
+	for index, trade := range pair.Trades {
+		if trade.Time.UnixNano() <= since {
+			continue
+		}
+		if len(entries) == tradesPage {
+			break
+		}
+
+		side := "b"
+		if trade.Side == domain.Sell {
+			side = "s"
+		}
+		entries = append(entries, []interface{}{
+			formatFloat(trade.Price),
+			formatFloat(trade.Volume),
+			float64(trade.Time.UnixNano()) / float64(time.Second),
+			side,
+			"m",
+			"",
+			index + 1,
+		})
+		last = strconv.FormatInt(trade.Time.UnixNano(), 10)
+	}
+
+	return map[string]interface{}{
+		form.Get("pair"): entries,
+		"last":           last,
+	}, ""
+}
@@ -0,0 +1,210 @@
+package fake
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Order is an order placed on the fake server. Market orders are filled at once, limit orders stay open until the
+// prices cross their limit price or they are canceled.
+type Order struct {
+	Id        string
+	Pair      string
+	Side      string
+	OrderType string
+	Volume    float64
+	// LimitPrice is the price of limit orders
+	LimitPrice float64
+
+	Status string
+	Filled float64
+	// Price is the execution price
+	Price float64
+	// Cost and Fee are in quote currency
+	Cost float64
+	Fee  float64
+}
+
+func (s *Server) balance(url.Values) (interface{}, string) {
+	result := map[string]string{}
+	for asset, quantity := range s.Balances {
+		result[asset] = strconv.FormatFloat(quantity, 'f', 10, 64)
+	}
+
+	return result, ""
+}
+
+func (s *Server) tradeVolume(form url.Values) (interface{}, string) {
+	pairs, message := s.requestedPairs(form)
+	if message != "" {
+		return nil, message
+	}
+
+	fees := map[string]interface{}{}
+	for name, pair := range pairs {
+		fees[name] = map[string]string{"fee": formatFloat(pair.Fee)}
+	}
+
+	return map[string]interface{}{"currency": "ZUSD", "volume": "0.0000", "fees": fees}, ""
+}
+
+func (s *Server) addOrder(form url.Values) (interface{}, string) {
+	pair, ok := s.Pairs[form.Get("pair")]
+	if !ok {
+		return nil, "EQuery:Unknown asset pair"
+	}
+
+	if s.Status == CancelOnly || pair.Status == CancelOnly {
+		return nil, "EService:Market in cancel_only mode"
+	}
+
+	order := &Order{
+		Pair:      form.Get("pair"),
+		Side:      form.Get("type"),
+		OrderType: form.Get("ordertype"),
+		Status:    "open",
+	}
+	if order.Side != "buy" && order.Side != "sell" {
+		return nil, "EGeneral:Invalid arguments:type"
+	}
+
+	if order.OrderType == "market" && (s.Status == PostOnly || pair.Status == PostOnly) {
+		return nil, "EService:Market in post_only mode"
+	}
+
+	var err error
+	order.Volume, err = strconv.ParseFloat(form.Get("volume"), 64)
+	if err != nil || order.Volume <= 0 {
+		return nil, "EGeneral:Invalid arguments:volume"
+	}
+
+	if order.Volume < pair.OrderMin {
+		return nil, "EOrder:Order minimum not met"
+	}
+
+	switch order.OrderType {
+	case "market":
+		order.Price = pair.Ask
+		if order.Side == "sell" {
+			order.Price = pair.Bid
+		}
+	case "limit":
+		order.LimitPrice, err = strconv.ParseFloat(form.Get("price"), 64)
+		if err != nil || order.LimitPrice <= 0 {
+			return nil, "EGeneral:Invalid arguments:price"
+		}
+		order.Price = order.LimitPrice
+	default:
+		return nil, "EGeneral:Invalid arguments:ordertype"
+	}
+
+	if !s.funded(order, pair) {
+		return nil, "EOrder:Insufficient funds"
+	}
+
+	description := map[string]string{"order": fmt.Sprintf("%s %s %s @ %s", order.Side, form.Get("volume"), order.Pair, order.OrderType)}
+	if form.Get("validate") == "true" {
+		return map[string]interface{}{"descr": description}, ""
+	}
+
+	s.orderId++
+	order.Id = fmt.Sprintf("O%05d-FAKE", s.orderId)
+	s.Orders[order.Id] = order
+	if order.OrderType == "market" {
+		s.fill(order, pair)
+	} else {
+		s.matchOrders(order.Pair)
+	}
+
+	return map[string]interface{}{"descr": description, "txid": []string{order.Id}}, ""
+}
+
+// funded Get whether the balances cover the order at its price, fees included
+func (s *Server) funded(order *Order, pair *Pair) bool {
+	if order.Side == "sell" {
+		return s.Balances[pair.Base] >= order.Volume
+	}
+
+	cost := order.Volume * order.Price
+
+	return s.Balances[pair.Quote] >= cost*(1+pair.Fee/100)
+}
+
+// fill Execute the whole order at its price, the fee being charged in quote currency
+func (s *Server) fill(order *Order, pair *Pair) {
+	order.Status = "closed"
+	order.Filled = order.Volume
+	order.Cost = order.Volume * order.Price
+	order.Fee = order.Cost * pair.Fee / 100
+
+	if order.Side == "buy" {
+		s.Balances[pair.Quote] -= order.Cost + order.Fee
+		s.Balances[pair.Base] += order.Volume
+	} else {
+		s.Balances[pair.Base] -= order.Volume
+		s.Balances[pair.Quote] += order.Cost - order.Fee
+	}
+}
+
+// matchOrders Fill the open limit orders of the pair crossed by its prices, if they are still funded
+func (s *Server) matchOrders(name string) {
+	pair := s.Pairs[name]
+	for _, order := range s.Orders {
+		if order.Pair != name || order.Status != "open" {
+			continue
+		}
+
+		crossed := order.Side == "buy" && pair.Ask <= order.LimitPrice || order.Side == "sell" && pair.Bid >= order.LimitPrice
+		if crossed && s.funded(order, pair) {
+			s.fill(order, pair)
+		}
+	}
+}
+
+func (s *Server) queryOrders(form url.Values) (interface{}, string) {
+	result := map[string]interface{}{}
+	for _, id := range strings.Split(form.Get("txid"), ",") {
+		order, ok := s.Orders[id]
+		if !ok {
+			return nil, "EOrder:Invalid order"
+		}
+
+		price := 0.0
+		if order.Filled > 0 {
+			price = order.Price
+		}
+
+		result[id] = map[string]interface{}{
+			"status": order.Status,
+			"descr": map[string]string{
+				"pair":      order.Pair,
+				"type":      order.Side,
+				"ordertype": order.OrderType,
+				"price":     formatFloat(order.LimitPrice),
+			},
+			"vol":      formatFloat(order.Volume),
+			"vol_exec": formatFloat(order.Filled),
+			"cost":     formatFloat(order.Cost),
+			"fee":      formatFloat(order.Fee),
+			"price":    formatFloat(price),
+		}
+	}
+
+	return result, ""
+}
+
+func (s *Server) cancelOrder(form url.Values) (interface{}, string) {
+	order, ok := s.Orders[form.Get("txid")]
+	if !ok {
+		return nil, "EOrder:Unknown order"
+	}
+
+	if order.Status != "open" {
+		return map[string]interface{}{"count": 0}, ""
+	}
+	order.Status = "canceled"
+
+	return map[string]interface{}{"count": 1}, ""
+}
@@ -0,0 +1,217 @@
+// Package fake provides an in-process stand-in of the Kraken REST and WebSocket APIs, so that the bot can be tested
+// offline.
+// It keeps balances and orders in memory, fills market orders at the ticker prices and can inject the errors Kraken
+// returns (rate limits, insufficient funds, maintenance).
+package fake
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// System statuses, as returned by the SystemStatus method
+const (
+	Online      = "online"
+	Maintenance = "maintenance"
+	CancelOnly  = "cancel_only"
+	PostOnly    = "post_only"
+)
+
+// Server is a fake Kraken API. Its state is exported to be set up and checked by tests, the mutex guarding it while
+// the server is running.
+type Server struct {
+	sync.Mutex
+
+	// Status is the system status, every method but SystemStatus and Time being unavailable during maintenance
+	Status string
+	// RateLimited makes every private method fail with the rate limit error
+	RateLimited bool
+	// Now is the server clock
+	Now func() time.Time
+
+	Balances map[string]float64
+	Pairs    map[string]*Pair
+	Orders   map[string]*Order
+
+	Strategies []Strategy
+	// Allocations is the amount allocated to each strategy id
+	Allocations map[string]float64
+
+	server  *httptest.Server
+	methods map[string]method
+	errors  map[string]string
+	orderId int
+
+	subscribers map[*subscriber]bool
+	channelId   int
+}
+
+// method handles the query of an API method, returning its result or a Kraken error message
+type method func(form url.Values) (interface{}, string)
+
+func NewServer() *Server {
+	s := &Server{
+		Status:      Online,
+		Now:         time.Now,
+		Balances:    map[string]float64{},
+		Pairs:       map[string]*Pair{},
+		Orders:      map[string]*Order{},
+		Allocations: map[string]float64{},
+		errors:      map[string]string{},
+		subscribers: map[*subscriber]bool{},
+	}
+	s.methods = map[string]method{
+		"public/Time":             s.time,
+		"public/SystemStatus":     s.systemStatus,
+		"public/AssetPairs":       s.assetPairs,
+		"public/Ticker":           s.ticker,
+		"public/OHLC":             s.ohlc,
+		"public/Trades":           s.trades,
+		"private/Balance":         s.balance,
+		"private/TradeVolume":     s.tradeVolume,
+		"private/AddOrder":        s.addOrder,
+		"private/QueryOrders":     s.queryOrders,
+		"private/CancelOrder":     s.cancelOrder,
+		"private/Earn/Strategies": s.strategies,
+		"private/Earn/Allocate":   s.allocate,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Client Get an HTTP client sending the requests addressed to the Kraken API to the fake server
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.server.URL)
+
+	return &http.Client{Transport: redirect{target: target, transport: s.server.Client().Transport}}
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Fail Make the given method (e.g. "AddOrder" or "Earn/Allocate") fail with the Kraken error message until reset
+// with an empty message
+func (s *Server) Fail(method string, message string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if message == "" {
+		delete(s.errors, method)
+
+		return
+	}
+	s.errors[method] = message
+}
+
+func (s *Server) serveHTTP(writer http.ResponseWriter, request *http.Request) {
+	// The WebSocket connections are long-lived, the lock is only taken to handle their messages
+	if request.URL.Path == "/ws" {
+		s.serveWebSocket(writer, request)
+
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	name := strings.TrimPrefix(request.URL.Path, "/0/")
+	handle, ok := s.methods[name]
+	if !ok {
+		s.respond(writer, nil, "EGeneral:Unknown method")
+
+		return
+	}
+
+	visibility, method := splitMethod(name)
+	if visibility == "private" && (request.Header.Get("API-Key") == "" || request.Header.Get("API-Sign") == "") {
+		s.respond(writer, nil, "EAPI:Invalid key")
+
+		return
+	}
+
+	if message := s.error(visibility, method); message != "" {
+		s.respond(writer, nil, message)
+
+		return
+	}
+
+	// The client library doesn't set the content type of its requests, the form is parsed from the body whatever it is
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		s.respond(writer, nil, "EGeneral:Invalid arguments")
+
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		s.respond(writer, nil, "EGeneral:Invalid arguments")
+
+		return
+	}
+
+	result, message := handle(form)
+	s.respond(writer, result, message)
+}
+
+// error Get the error message injected for the method, if any
+func (s *Server) error(visibility string, method string) string {
+	if message, ok := s.errors[method]; ok {
+		return message
+	}
+
+	if s.Status == Maintenance && method != "SystemStatus" && method != "Time" {
+		return "EService:Unavailable"
+	}
+
+	if s.RateLimited && visibility == "private" {
+		return "EAPI:Rate limit exceeded"
+	}
+
+	return ""
+}
+
+// respond Write the Kraken response envelope, with the result or the error message
+func (s *Server) respond(writer http.ResponseWriter, result interface{}, message string) {
+	response := map[string]interface{}{"error": []string{}, "result": result}
+	if message != "" {
+		response = map[string]interface{}{"error": []string{message}}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+func splitMethod(name string) (string, string) {
+	parts := strings.SplitN(name, "/", 2)
+
+	return parts[0], parts[1]
+}
+
+// redirect sends the requests to the target server, whatever their original host
+type redirect struct {
+	target    *url.URL
+	transport http.RoundTripper
+}
+
+func (r redirect) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.URL.Scheme = r.target.Scheme
+	request.URL.Host = r.target.Host
+	request.Host = r.target.Host
+
+	return r.transport.RoundTrip(request)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,199 @@
+package fake
+
+import (
+	"github.com/gorilla/websocket"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// heartbeatInterval is the delay between the heartbeats sent to the subscribers, as Kraken does without traffic
+const heartbeatInterval = time.Second
+
+// bookChannel is the name of the book channel, the fake book having a single level per side
+const bookChannel = "book-10"
+
+// subscriber is a WebSocket client of the fake server
+type subscriber struct {
+	// mutex serializes the writes to the connection
+	mutex      sync.Mutex
+	connection *websocket.Conn
+	// channels are the ids of the subscribed channels by name, then by pair
+	channels map[string]map[string]int
+}
+
+// WebSocketURL Get the URL of the fake Kraken WebSocket API, streaming the ticker and book channels of the pairs
+func (s *Server) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws"
+}
+
+// Disconnect Close the connections of the WebSocket subscribers, as when the WebSocket API restarts
+func (s *Server) Disconnect() {
+	s.Lock()
+	defer s.Unlock()
+
+	for client := range s.subscribers {
+		_ = client.connection.Close()
+	}
+}
+
+// serveWebSocket Handle a WebSocket connection, answering its subscriptions until it's closed
+func (s *Server) serveWebSocket(writer http.ResponseWriter, request *http.Request) {
+	connection, err := (&websocket.Upgrader{}).Upgrade(writer, request, nil)
+	if err != nil {
+		return
+	}
+
+	client := &subscriber{connection: connection, channels: map[string]map[string]int{}}
+	s.Lock()
+	s.subscribers[client] = true
+	s.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		s.Lock()
+		delete(s.subscribers, client)
+		s.Unlock()
+		_ = connection.Close()
+	}()
+
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				client.send(map[string]string{"event": "heartbeat"})
+			}
+		}
+	}()
+
+	client.send(map[string]interface{}{"event": "systemStatus", "status": "online", "version": "1.9.0"})
+	for {
+		var message struct {
+			Event        string   `json:"event"`
+			Pair         []string `json:"pair"`
+			Subscription struct {
+				Name string `json:"name"`
+			} `json:"subscription"`
+		}
+		err = connection.ReadJSON(&message)
+		if err != nil {
+			return
+		}
+
+		if message.Event == "subscribe" {
+			s.subscribe(client, message.Pair, message.Subscription.Name)
+		}
+	}
+}
+
+// subscribe Subscribe the client to the channel of the pairs, by WebSocket name, and send their current prices
+func (s *Server) subscribe(client *subscriber, names []string, channel string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if channel == "book" {
+		channel = bookChannel
+	}
+
+	for _, name := range names {
+		status := map[string]interface{}{"event": "subscriptionStatus", "pair": name, "channelName": channel}
+		pair, ok := s.pairByWsName(name)
+		if !ok || (channel != "ticker" && channel != bookChannel) {
+			status["status"] = "error"
+			status["errorMessage"] = "Currency pair not supported"
+			client.send(status)
+
+			continue
+		}
+
+		s.channelId++
+		if client.channels[channel] == nil {
+			client.channels[channel] = map[string]int{}
+		}
+		client.channels[channel][name] = s.channelId
+		status["status"] = "subscribed"
+		status["channelID"] = s.channelId
+		client.send(status)
+
+		if channel == "ticker" {
+			client.send(tickerMessage(s.channelId, pair, name))
+		} else {
+			client.send([]interface{}{s.channelId, map[string]interface{}{
+				"as": [][]string{{formatFloat(pair.Ask), "1.00000000", timestamp(s.Now())}},
+				"bs": [][]string{{formatFloat(pair.Bid), "1.00000000", timestamp(s.Now())}},
+			}, channel, name})
+		}
+	}
+}
+
+// publish Send the new prices of the pair to its subscribers, the book update removing the previous levels
+func (s *Server) publish(pairName string, previousAsk float64, previousBid float64) {
+	pair := s.Pairs[pairName]
+	name := wsName(pairName, pair)
+	for client := range s.subscribers {
+		if id, ok := client.channels["ticker"][name]; ok {
+			client.send(tickerMessage(id, pair, name))
+		}
+
+		if id, ok := client.channels[bookChannel][name]; ok {
+			update := map[string]interface{}{}
+			for field, levels := range map[string][2]float64{"a": {previousAsk, pair.Ask}, "b": {previousBid, pair.Bid}} {
+				if levels[0] != levels[1] {
+					update[field] = [][]string{
+						{formatFloat(levels[0]), "0.00000000", timestamp(s.Now())},
+						{formatFloat(levels[1]), "1.00000000", timestamp(s.Now())},
+					}
+				}
+			}
+			if len(update) > 0 {
+				client.send([]interface{}{id, update, bookChannel, name})
+			}
+		}
+	}
+}
+
+// pairByWsName Get the pair with the WebSocket name
+func (s *Server) pairByWsName(name string) (*Pair, bool) {
+	for pairName, pair := range s.Pairs {
+		if wsName(pairName, pair) == name {
+			return pair, true
+		}
+	}
+
+	return nil, false
+}
+
+// send Write the message to the client, the connection errors ending its read loop
+func (c *subscriber) send(message interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_ = c.connection.WriteJSON(message)
+}
+
+// wsName Get the WebSocket name of the pair, its name if it has none
+func wsName(name string, pair *Pair) string {
+	if pair.WsName != "" {
+		return pair.WsName
+	}
+
+	return name
+}
+
+func tickerMessage(id int, pair *Pair, name string) []interface{} {
+	return []interface{}{id, map[string]interface{}{
+		"a": []string{formatFloat(pair.Ask), "1", "1.00000000"},
+		"b": []string{formatFloat(pair.Bid), "1", "1.00000000"},
+		"c": []string{formatFloat(pair.Bid), "0.10000000"},
+	}, "ticker", name}
+}
+
+func timestamp(time time.Time) string {
+	return formatFloat(float64(time.UnixMicro()) / 1e6)
+}
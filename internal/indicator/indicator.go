@@ -0,0 +1,73 @@
+// Package indicator computes technical indicators from a pair price history
+package indicator
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"math"
+)
+
+// SMA Get the simple moving average of the last `period` closing prices
+func SMA(candles []domain.Candle, period int) (float64, error) {
+	if period <= 0 || len(candles) < period {
+		return -1, fmt.Errorf("%d candles are needed to compute a %d-period moving average, got %d", period, period, len(candles))
+	}
+
+	sum := 0.0
+	for _, candle := range candles[len(candles)-period:] {
+		sum += candle.Close
+	}
+
+	return sum / float64(period), nil
+}
+
+// RSI Get the relative strength index of the closing prices using Wilder's smoothing over `period` candles
+func RSI(candles []domain.Candle, period int) (float64, error) {
+	if period <= 0 || len(candles) <= period {
+		return -1, fmt.Errorf("%d candles are needed to compute a %d-period RSI, got %d", period+1, period, len(candles))
+	}
+
+	gain, loss := 0.0, 0.0
+	for index := 1; index < len(candles); index++ {
+		change := candles[index].Close - candles[index-1].Close
+		currentGain, currentLoss := math.Max(change, 0), math.Max(-change, 0)
+
+		if index <= period {
+			gain += currentGain / float64(period)
+			loss += currentLoss / float64(period)
+			continue
+		}
+
+		gain = (gain*float64(period-1) + currentGain) / float64(period)
+		loss = (loss*float64(period-1) + currentLoss) / float64(period)
+	}
+
+	if loss == 0 {
+		return 100, nil
+	}
+
+	return 100 - 100/(1+gain/loss), nil
+}
+
+// ATR Get the average true range over `period` candles using Wilder's smoothing
+func ATR(candles []domain.Candle, period int) (float64, error) {
+	if period <= 0 || len(candles) <= period {
+		return -1, fmt.Errorf("%d candles are needed to compute a %d-period ATR, got %d", period+1, period, len(candles))
+	}
+
+	atr := 0.0
+	for index := 1; index < len(candles); index++ {
+		previousClose := candles[index-1].Close
+		trueRange := math.Max(candles[index].High-candles[index].Low,
+			math.Max(math.Abs(candles[index].High-previousClose), math.Abs(candles[index].Low-previousClose)))
+
+		if index <= period {
+			atr += trueRange / float64(period)
+			continue
+		}
+
+		atr = (atr*float64(period-1) + trueRange) / float64(period)
+	}
+
+	return atr, nil
+}
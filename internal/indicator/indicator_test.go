@@ -0,0 +1,64 @@
+package indicator
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"math"
+	"testing"
+)
+
+func closes(values ...float64) []domain.Candle {
+	candles := make([]domain.Candle, len(values))
+	for index, value := range values {
+		candles[index] = domain.Candle{Open: value, High: value, Low: value, Close: value}
+	}
+
+	return candles
+}
+
+func TestSMA(t *testing.T) {
+	sma, err := SMA(closes(1, 2, 3, 4, 5), 3)
+	if err != nil || sma != 4 {
+		t.Errorf("The SMA is %f (%v) instead of 4", sma, err)
+	}
+
+	_, err = SMA(closes(1, 2), 3)
+	if err == nil || err.Error() != "3 candles are needed to compute a 3-period moving average, got 2" {
+		t.Errorf("An unexpected error was returned : %v", err)
+	}
+}
+
+func TestRSI(t *testing.T) {
+	rsi, err := RSI(closes(10, 11, 12, 13), 3)
+	if err != nil || rsi != 100 {
+		t.Errorf("The RSI is %f (%v) instead of 100", rsi, err)
+	}
+
+	rsi, err = RSI(closes(10, 12, 11, 13, 12), 2)
+	if err != nil || math.Abs(rsi-600.0/11) > 1e-9 {
+		t.Errorf("The RSI is %f (%v) instead of 54.545454", rsi, err)
+	}
+
+	_, err = RSI(closes(10, 11), 3)
+	if err == nil {
+		t.Error("No error was returned for a too short history")
+	}
+}
+
+func TestATR(t *testing.T) {
+	candles := []domain.Candle{
+		{High: 10, Low: 8, Close: 9},
+		{High: 11, Low: 9, Close: 10},
+		{High: 12, Low: 9, Close: 11},
+		{High: 11, Low: 10, Close: 10},
+	}
+
+	atr, err := ATR(candles, 2)
+	if err != nil || math.Abs(atr-1.75) > 1e-9 {
+		t.Errorf("The ATR is %f (%v) instead of 1.75", atr, err)
+	}
+
+	_, err = ATR(candles, 4)
+	if err == nil {
+		t.Error("No error was returned for a too short history")
+	}
+}
@@ -1,27 +1,81 @@
 package kraken
 
-import "reflect"
+import (
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+)
 
 //go:generate mockgen -destination=../mocks/mock_account_service.go -package=mocks . Account
 
 type Account interface {
 	Balance(currency string) (float64, error)
+	Holdings(asset string) (float64, error)
+	Ledgers(asset string, ledgerType string) ([]domain.Ledger, error)
+	Stake(asset string, amount float64, strategy string) (string, error)
+	WithdrawalFee(asset string, key string, amount float64) (float64, error)
+	Withdraw(asset string, key string, amount float64) (string, error)
 }
 
 type AccountService struct {
-	api ApiInterface
+	exchange exchange.Exchange
 }
 
-func NewAccount(api ApiInterface) Account {
-	return AccountService{api: api}
+func NewAccount(exchange exchange.Exchange) Account {
+	return AccountService{exchange: exchange}
 }
 
-// Balance Get the Kraken account balance in Euros
+// Balance Get the account balance in the given quote currency
 func (a AccountService) Balance(currency string) (float64, error) {
-	balance, err := a.api.Balance()
+	return a.Holdings(currency)
+}
+
+// Holdings Get the quantity of the given asset held on the account, an asset missing from the account being held at 0
+func (a AccountService) Holdings(asset string) (float64, error) {
+	balances, err := a.exchange.Balances()
 	if err != nil {
 		return -1, err
 	}
 
-	return reflect.ValueOf(*balance).FieldByName(currency).Float(), nil
+	return balances[asset], nil
+}
+
+// Ledgers Get the latest ledger entries of the given asset and type (e.g. deposit), oldest first
+func (a AccountService) Ledgers(asset string, ledgerType string) ([]domain.Ledger, error) {
+	funding, ok := a.exchange.(exchange.Funding)
+	if !ok {
+		return nil, exchange.ErrUnsupported
+	}
+
+	return funding.Ledgers(asset, ledgerType)
+}
+
+// Stake Allocate `amount` of the asset to the given staking strategy, or to the first strategy offered for the asset
+// if `strategy` is empty, and get the id of the strategy
+func (a AccountService) Stake(asset string, amount float64, strategy string) (string, error) {
+	earning, ok := a.exchange.(exchange.Earning)
+	if !ok {
+		return "", exchange.ErrUnsupported
+	}
+
+	return earning.Stake(asset, amount, strategy)
+}
+
+// WithdrawalFee Get the fee of withdrawing `amount` of the asset to the withdrawal key without withdrawing anything
+func (a AccountService) WithdrawalFee(asset string, key string, amount float64) (float64, error) {
+	funding, ok := a.exchange.(exchange.Funding)
+	if !ok {
+		return -1, exchange.ErrUnsupported
+	}
+
+	return funding.WithdrawalFee(asset, key, amount)
+}
+
+// Withdraw Withdraw `amount` of the asset to the withdrawal key and get the withdrawal reference id
+func (a AccountService) Withdraw(asset string, key string, amount float64) (string, error) {
+	funding, ok := a.exchange.(exchange.Funding)
+	if !ok {
+		return "", exchange.ErrUnsupported
+	}
+
+	return funding.Withdraw(asset, key, amount)
 }
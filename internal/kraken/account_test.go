@@ -2,8 +2,9 @@ package kraken
 
 import (
 	"errors"
-	krakenapi "github.com/beldur/kraken-go-api-client"
+	"fmt"
 	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/fake"
 	"kraken-dca-bot/internal/mocks"
 	"testing"
 )
@@ -22,9 +23,11 @@ func TestBalanceSuccess(t *testing.T) {
 
 	for _, c := range cases {
 		krakenApi := mocks.NewMockApiInterface(controller)
-		accountService := NewAccount(krakenApi)
+		accountService := NewAccount(NewExchange(krakenApi))
 
-		krakenApi.EXPECT().Balance().Return(&krakenapi.BalanceResponse{ZEUR: c.balance}, c.error)
+		krakenApi.EXPECT().Query("Balance", map[string]string{}).Return(map[string]interface{}{
+			"ZEUR": fmt.Sprint(c.balance),
+		}, c.error)
 
 		balance, err := accountService.Balance("ZEUR")
 
@@ -37,3 +40,126 @@ func TestBalanceSuccess(t *testing.T) {
 		}
 	}
 }
+
+func TestHoldings(t *testing.T) {
+	cases := []struct {
+		asset    string
+		error    error
+		holdings float64
+	}{
+		{"DOT", nil, 12.5},
+		{"XXBT", nil, 0},
+		{"DOT", errors.New("balance error"), -1},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	for _, c := range cases {
+		krakenApi := mocks.NewMockApiInterface(controller)
+		accountService := NewAccount(NewExchange(krakenApi))
+
+		krakenApi.EXPECT().Query("Balance", map[string]string{}).Return(map[string]interface{}{
+			"ZEUR": "154.2300",
+			"DOT":  "12.5000000000",
+		}, c.error)
+
+		holdings, err := accountService.Holdings(c.asset)
+
+		if err != c.error {
+			t.Errorf("An unexpected error was returned : %v", err)
+		}
+
+		if holdings != c.holdings {
+			t.Errorf("%s holdings returned %f instead of %f", c.asset, holdings, c.holdings)
+		}
+	}
+}
+
+func TestLedgers(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	accountService := NewAccount(NewExchange(krakenApi))
+
+	krakenApi.EXPECT().Query("Ledgers", map[string]string{"asset": "ZEUR", "type": "deposit"}).Return(map[string]interface{}{
+		"ledger": map[string]interface{}{
+			"L2": map[string]interface{}{"time": 1665500000.5, "type": "deposit", "asset": "ZEUR", "amount": "300.0000", "fee": "0.0000"},
+			"L1": map[string]interface{}{"time": 1665400000.25, "type": "deposit", "asset": "ZEUR", "amount": "500.0000", "fee": "1.0000"},
+		},
+		"count": float64(2),
+	}, nil)
+
+	ledgers, err := accountService.Ledgers("ZEUR", "deposit")
+	if err != nil {
+		t.Errorf("An unexpected error was returned : %v", err)
+	}
+
+	if len(ledgers) != 2 || ledgers[0].Id != "L1" || ledgers[0].Net() != 499 || ledgers[1].Id != "L2" || ledgers[1].Time.Unix() != 1665500000 {
+		t.Errorf("The ledgers are %v", ledgers)
+	}
+}
+
+func TestStake(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Strategies = []fake.Strategy{{Id: "ETH-FLEX", Asset: "ETH"}, {Id: "DOT-BONDED", Asset: "DOT"}}
+
+	accountService := NewAccount(NewExchange(NewApiWithClient("key", "c2VjcmV0", server.Client())))
+
+	strategy, err := accountService.Stake("DOT", 1.5, "")
+	if err != nil || strategy != "DOT-BONDED" {
+		t.Errorf("The stake returned %v, %v", strategy, err)
+	}
+
+	strategy, err = accountService.Stake("ETH", 0.25, "ETH-FLEX")
+	if err != nil || strategy != "ETH-FLEX" {
+		t.Errorf("The stake returned %v, %v", strategy, err)
+	}
+
+	if server.Allocations["DOT-BONDED"] != 1.5 || server.Allocations["ETH-FLEX"] != 0.25 {
+		t.Errorf("The allocations are %v", server.Allocations)
+	}
+
+	_, err = accountService.Stake("ADA", 10, "")
+	if err == nil || err.Error() != "no earn strategy is offered for ADA" {
+		t.Errorf("No relevant error was returned : %v", err)
+	}
+
+	server.Fail("Earn/Allocate", "EService:Unavailable")
+	_, err = accountService.Stake("ETH", 0.25, "ETH-FLEX")
+	if err == nil || err.Error() != "the Earn/Allocate request was refused : EService:Unavailable" {
+		t.Errorf("No relevant error was returned : %v", err)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	accountService := NewAccount(NewExchange(krakenApi))
+
+	args := map[string]string{"asset": "XXBT", "key": "ledger", "amount": "0.5"}
+	krakenApi.EXPECT().Query("WithdrawInfo", args).Return(map[string]interface{}{
+		"method": "Bitcoin", "limit": "1.2", "amount": "0.4995", "fee": "0.0005",
+	}, nil)
+	krakenApi.EXPECT().Query("Withdraw", args).Return(map[string]interface{}{"refid": "REF"}, nil)
+
+	fee, err := accountService.WithdrawalFee("XXBT", "ledger", 0.5)
+	if err != nil || fee != 0.0005 {
+		t.Errorf("The withdrawal fee is %v, %v", fee, err)
+	}
+
+	refId, err := accountService.Withdraw("XXBT", "ledger", 0.5)
+	if err != nil || refId != "REF" {
+		t.Errorf("The withdrawal returned %v, %v", refId, err)
+	}
+
+	krakenApi.EXPECT().Query("WithdrawInfo", gomock.Any()).Return(map[string]interface{}{"limit": "0.1", "fee": "0.0005"}, nil)
+	_, err = accountService.WithdrawalFee("XXBT", "ledger", 0.5)
+	if err == nil || err.Error() != "the 0.500000 XXBT withdrawal exceeds the 0.100000 limit" {
+		t.Errorf("No relevant error was returned : %v", err)
+	}
+}
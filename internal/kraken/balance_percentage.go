@@ -0,0 +1,23 @@
+package kraken
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// balancePercentageAmount Get the pair percentage of the quote currency balance snapshot taken at the beginning of
+// the round, so that the percentages of successive pairs don't compound
+func (i investingService) balancePercentageAmount(pair domain.DCAPair, round round) (float64, error) {
+	if round.balanceErr != nil {
+		return -1, round.balanceErr
+	}
+
+	amount := pair.BalancePercentage.Amount(round.balance)
+	log.Printf("[%s] %.2f%% of the %.2f€ balance - Amount : %.2f€", pair.Pair, pair.BalancePercentage.Percentage, round.balance, amount)
+
+	if amount <= 0 {
+		return 0, skipError{reason: "the balance percentage amount is null"}
+	}
+
+	return amount, nil
+}
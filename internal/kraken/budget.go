@@ -0,0 +1,32 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// budgetAmount Get the pair share of the monthly budget to invest this round and the part of the monthly share left,
+// given what was already spent on the pair this month according to the transaction history
+func (i investingService) budgetAmount(pair domain.DCAPair) (float64, float64, error) {
+	period, err := i.config.Period()
+	if err != nil {
+		return -1, -1, fmt.Errorf("cannot parse the DCA frequency : %w", err)
+	}
+
+	transactions, err := i.history.Transactions()
+	if err != nil {
+		return -1, -1, fmt.Errorf("cannot load the transaction history : %w", err)
+	}
+
+	now := i.now()
+	spent := domain.BudgetSpent(transactions, pair.Pair, now)
+	amount := i.config.Budget.Amount(pair.Pair, spent, now, period)
+	log.Printf("[%s] Monthly budget : %.2f€ spent, %d rounds left - Amount : %.2f€", pair.Pair, spent, domain.RoundsLeftInMonth(now, period), amount)
+
+	if amount <= 0 {
+		return 0, 0, skipError{reason: "the monthly budget is already spent"}
+	}
+
+	return amount, i.config.Budget.Remaining(pair.Pair, spent), nil
+}
@@ -0,0 +1,34 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+)
+
+// checkCaps Get an error if investing the pair amount exceeds the global or the pair spending caps, given the
+// transaction history
+func (i investingService) checkCaps(pair domain.DCAPair) error {
+	if i.config.Caps == (domain.Caps{}) && pair.Caps == nil {
+		return nil
+	}
+
+	transactions, err := i.history.Transactions()
+	if err != nil {
+		return fmt.Errorf("cannot load the transaction history : %w", err)
+	}
+
+	now := i.now()
+	err = i.config.Caps.Check(domain.Spent(transactions, "", now), pair.Amount)
+	if err != nil {
+		return fmt.Errorf("global spending cap : %w", err)
+	}
+
+	if pair.Caps != nil {
+		err = pair.Caps.Check(domain.Spent(transactions, pair.Pair, now), pair.Amount)
+		if err != nil {
+			return fmt.Errorf("%s spending cap : %w", pair.Pair, err)
+		}
+	}
+
+	return nil
+}
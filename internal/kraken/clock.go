@@ -0,0 +1,93 @@
+package kraken
+
+//go:generate mockgen -destination=../mocks/mock_clock.go -package=mocks . Clock
+
+import (
+	"errors"
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrClockDrift is returned when the local clock drifts from the exchange time by more than the maximum drift
+var ErrClockDrift = errors.New("the local clock drifts too much from the exchange time")
+
+type Clock interface {
+	// Sync Measure the drift of the local clock from the exchange time and get it
+	Sync() (time.Duration, error)
+	// Now Get the local time corrected by the last measured drift
+	Now() time.Time
+}
+
+type clock struct {
+	exchange  exchange.Exchange
+	warnDrift time.Duration
+	maxDrift  time.Duration
+	local     func() time.Time
+
+	mutex  sync.Mutex
+	offset time.Duration
+}
+
+func NewClock(exchange exchange.Exchange, config domain.Clock) (Clock, error) {
+	warnDrift, maxDrift, err := config.Drifts()
+	if err != nil {
+		return nil, err
+	}
+
+	return &clock{exchange: exchange, warnDrift: warnDrift, maxDrift: maxDrift, local: time.Now}, nil
+}
+
+// Sync Measure the drift of the local clock from the exchange time, the offset then correcting Now. The exchange time
+// is compared to the middle of the request, the drift being accurate to half a second as Kraken serves its time to the
+// second. A drift above the maximum one is returned with ErrClockDrift, and the local time is used as is when the
+// exchange doesn't serve its time.
+func (c *clock) Sync() (time.Duration, error) {
+	server, ok := c.exchange.(exchange.ServerTime)
+	if !ok {
+		return 0, nil
+	}
+
+	before := c.local()
+	serverTime, err := server.ServerTime()
+	if err != nil {
+		return 0, fmt.Errorf("the server time cannot be retrieved : %w", err)
+	}
+	after := c.local()
+
+	local := before.Add(after.Sub(before) / 2)
+	drift := serverTime.Add(500 * time.Millisecond).Sub(local).Round(time.Millisecond)
+
+	c.mutex.Lock()
+	c.offset = drift
+	c.mutex.Unlock()
+
+	if abs(drift) > c.maxDrift {
+		return drift, fmt.Errorf("%w : %s above %s", ErrClockDrift, drift, c.maxDrift)
+	}
+
+	if abs(drift) > c.warnDrift {
+		log.Printf("The local clock drifts by %s from the exchange time, check its synchronization (e.g. NTP)", drift)
+	}
+
+	return drift, nil
+}
+
+// Now Get the local time corrected by the last measured drift
+func (c *clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.local().Add(c.offset)
+}
+
+func abs(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+
+	return duration
+}
@@ -0,0 +1,66 @@
+package kraken
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/mocks"
+	"testing"
+	"time"
+)
+
+func TestClockSync(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Now = func() time.Time {
+		return time.Date(2022, 6, 1, 12, 0, 10, 0, time.UTC)
+	}
+
+	krakenClock, err := NewClock(NewExchange(NewApiWithClient("key", "c2VjcmV0", server.Client())), domain.Clock{WarnDrift: "5s", MaxDrift: "30s"})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+	local := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	krakenClock.(*clock).local = func() time.Time {
+		return local
+	}
+
+	// The server time is served to the second, the drift is measured from the middle of its second
+	drift, err := krakenClock.Sync()
+	if err != nil || drift != 10500*time.Millisecond {
+		t.Errorf("The drift is %s (%v)", drift, err)
+	}
+
+	if now := krakenClock.Now(); !now.Equal(local.Add(drift)) {
+		t.Errorf("The corrected time is %s", now)
+	}
+
+	local = local.Add(-time.Minute)
+	drift, err = krakenClock.Sync()
+	if !errors.Is(err, ErrClockDrift) || drift != 70500*time.Millisecond {
+		t.Errorf("The drift should be refused : %s (%v)", drift, err)
+	}
+}
+
+func TestClockUnsupported(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenClock, err := NewClock(mocks.NewMockExchange(controller), domain.Clock{WarnDrift: "5s", MaxDrift: "30s"})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	drift, err := krakenClock.Sync()
+	if err != nil || drift != 0 {
+		t.Errorf("The drift is %s (%v)", drift, err)
+	}
+}
+
+func TestClockInvalidDrifts(t *testing.T) {
+	_, err := NewClock(nil, domain.Clock{WarnDrift: "1m", MaxDrift: "30s"})
+	if err == nil {
+		t.Error("The warning drift above the max drift should be rejected")
+	}
+}
@@ -0,0 +1,68 @@
+package kraken
+
+import (
+	"log"
+	"time"
+)
+
+// deploymentShare is the part of a deployment plan round slice allocated to a pair
+type deploymentShare struct {
+	plan   string
+	amount float64
+}
+
+func deploymentKey(plan string) string {
+	return "deployment/" + plan
+}
+
+// deploymentShares Get, for each pair, the deployment plan amounts to invest during the round happening at `now`.
+// The slices are computed once per round from the deployment progress saved in the state.
+func (i investingService) deploymentShares(now time.Time) map[string][]deploymentShare {
+	shares := map[string][]deploymentShare{}
+	if len(i.config.Deployments) == 0 {
+		return shares
+	}
+
+	period, err := i.config.Period()
+	if err != nil {
+		log.Printf("The deployment plans are ignored, the DCA frequency cannot be parsed : %v", err)
+
+		return shares
+	}
+
+	for _, plan := range i.config.Deployments {
+		deployed := 0.0
+		_, err := i.state.Load(deploymentKey(plan.Name), &deployed)
+		if err != nil {
+			log.Printf("The %s deployment plan is ignored, its progress cannot be loaded : %v", plan.Name, err)
+			continue
+		}
+
+		slice := plan.Slice(deployed, now, period)
+		log.Printf("[%s] Deployment plan : %.2f€ deployed out of %.2f€, %.2f€ to deploy this round", plan.Name, deployed, plan.Total, slice)
+		if slice <= 0 {
+			continue
+		}
+
+		for pair, percentage := range plan.Allocation {
+			shares[pair] = append(shares[pair], deploymentShare{plan: plan.Name, amount: slice * percentage / 100})
+		}
+	}
+
+	return shares
+}
+
+// recordDeployment Add the shares invested by a successful order to their deployment plan progress
+func (i investingService) recordDeployment(shares []deploymentShare) {
+	for _, share := range shares {
+		deployed := 0.0
+		_, err := i.state.Load(deploymentKey(share.plan), &deployed)
+		if err == nil {
+			err = i.state.Save(deploymentKey(share.plan), deployed+share.amount)
+		}
+
+		if err != nil {
+			log.Printf("The %s deployment plan progress could not be saved : %v", share.plan, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package kraken
+
+//go:generate mockgen -destination=../mocks/mock_deposit_watcher.go -package=mocks . DepositWatcher
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/storage"
+	"log"
+	"time"
+)
+
+// lastDepositKey is the state key of the last deposit seen by the watcher
+const lastDepositKey = "deposits/last"
+
+type DepositWatcher interface {
+	// Poll Get the deposits received since the last poll
+	Poll() ([]domain.Ledger, error)
+}
+
+type depositWatcher struct {
+	currency       string
+	accountService Account
+	state          storage.State
+	// now is the clock dating the first poll of an account without deposits
+	now func() time.Time
+}
+
+// lastDeposit identifies the last deposit seen by the watcher, its id being empty when the first poll found no deposit
+type lastDeposit struct {
+	Id   string
+	Time time.Time
+}
+
+func NewDepositWatcher(currency string, accountService Account, state storage.State, now func() time.Time) DepositWatcher {
+	return depositWatcher{
+		currency:       currency,
+		accountService: accountService,
+		state:          state,
+		now:            now,
+	}
+}
+
+// Poll Get the deposits of the configured currency received since the last seen deposit, which is persisted in the
+// state. The first poll only records the latest deposit, or its own time when there is none yet, so that past deposits
+// are not invested.
+func (w depositWatcher) Poll() ([]domain.Ledger, error) {
+	ledgers, err := w.accountService.Ledgers(w.currency, "deposit")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the %s deposits : %w", w.currency, err)
+	}
+
+	var last lastDeposit
+	found, err := w.state.Load(lastDepositKey, &last)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found && len(ledgers) == 0 {
+		now := w.now()
+		log.Printf("Watching the %s deposits made after %s", w.currency, now.Format(time.RFC3339))
+
+		return nil, w.state.Save(lastDepositKey, lastDeposit{Time: now})
+	}
+
+	if len(ledgers) == 0 {
+		return nil, nil
+	}
+
+	latest := ledgers[len(ledgers)-1]
+	if !found {
+		log.Printf("Watching the %s deposits made after %s", w.currency, latest.Id)
+
+		return nil, w.state.Save(lastDepositKey, lastDeposit{Id: latest.Id, Time: latest.Time})
+	}
+
+	var deposits []domain.Ledger
+	for _, ledger := range ledgers {
+		if ledger.Time.After(last.Time) && ledger.Id != last.Id {
+			deposits = append(deposits, ledger)
+		}
+	}
+
+	if len(deposits) > 0 {
+		err = w.state.Save(lastDepositKey, lastDeposit{Id: latest.Id, Time: latest.Time})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return deposits, nil
+}
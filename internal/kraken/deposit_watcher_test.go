@@ -0,0 +1,98 @@
+package kraken
+
+import (
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/mocks"
+	"testing"
+	"time"
+)
+
+var ledgers = []domain.Ledger{
+	{Id: "L1", Time: time.Unix(1665400000, 0), Type: "deposit", Asset: "ZEUR", Amount: 500},
+	{Id: "L2", Time: time.Unix(1665500000, 0), Type: "deposit", Asset: "ZEUR", Amount: 300},
+	{Id: "L3", Time: time.Unix(1665600000, 0), Type: "deposit", Asset: "ZEUR", Amount: 200},
+}
+
+func TestDepositWatcherFirstPoll(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	state := mocks.NewMockState(controller)
+	watcher := NewDepositWatcher("ZEUR", accountService, state, time.Now)
+
+	accountService.EXPECT().Ledgers("ZEUR", "deposit").Return(ledgers, nil)
+	state.EXPECT().Load("deposits/last", gomock.Any()).Return(false, nil)
+	state.EXPECT().Save("deposits/last", lastDeposit{Id: "L3", Time: time.Unix(1665600000, 0)}).Return(nil)
+
+	deposits, err := watcher.Poll()
+	if err != nil || len(deposits) != 0 {
+		t.Errorf("The first poll returned %v (%v)", deposits, err)
+	}
+}
+
+func TestDepositWatcherFirstPollWithoutDeposit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	state := mocks.NewMockState(controller)
+	now := time.Unix(1665300000, 0)
+	watcher := NewDepositWatcher("ZEUR", accountService, state, func() time.Time { return now })
+
+	// The first poll of an account without deposit records its own time
+	var saved lastDeposit
+	gomock.InOrder(
+		accountService.EXPECT().Ledgers("ZEUR", "deposit").Return(nil, nil),
+		state.EXPECT().Load("deposits/last", gomock.Any()).Return(false, nil),
+		state.EXPECT().Save("deposits/last", lastDeposit{Time: now}).DoAndReturn(func(key string, value interface{}) error {
+			saved = value.(lastDeposit)
+
+			return nil
+		}),
+	)
+
+	deposits, err := watcher.Poll()
+	if err != nil || len(deposits) != 0 {
+		t.Errorf("The first poll returned %v (%v)", deposits, err)
+	}
+
+	// The first deposit of the account is then invested
+	gomock.InOrder(
+		accountService.EXPECT().Ledgers("ZEUR", "deposit").Return(ledgers[:1], nil),
+		state.EXPECT().Load("deposits/last", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+			*value.(*lastDeposit) = saved
+
+			return true, nil
+		}),
+		state.EXPECT().Save("deposits/last", lastDeposit{Id: "L1", Time: time.Unix(1665400000, 0)}).Return(nil),
+	)
+
+	deposits, err = watcher.Poll()
+	if err != nil || len(deposits) != 1 || deposits[0].Id != "L1" {
+		t.Errorf("The poll returned %v (%v)", deposits, err)
+	}
+}
+
+func TestDepositWatcherPoll(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	state := mocks.NewMockState(controller)
+	watcher := NewDepositWatcher("ZEUR", accountService, state, time.Now)
+
+	accountService.EXPECT().Ledgers("ZEUR", "deposit").Return(ledgers, nil)
+	state.EXPECT().Load("deposits/last", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*lastDeposit) = lastDeposit{Id: "L1", Time: time.Unix(1665400000, 0)}
+
+		return true, nil
+	})
+	state.EXPECT().Save("deposits/last", lastDeposit{Id: "L3", Time: time.Unix(1665600000, 0)}).Return(nil)
+
+	deposits, err := watcher.Poll()
+	if err != nil || len(deposits) != 2 || deposits[0].Id != "L2" || deposits[1].Id != "L3" {
+		t.Errorf("The poll returned %v (%v)", deposits, err)
+	}
+}
@@ -0,0 +1,33 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// dailyInterval is the OHLC interval, in minutes, of daily candles
+const dailyInterval = 1440
+
+// drawdownMultiplier Get the multiplier to apply to the pair amount given the drawdown of its ask price from its
+// highest daily price over the configured number of days
+func (i investingService) drawdownMultiplier(pair domain.DCAPair) (float64, string, error) {
+	candles, err := i.tradingService.Candles(pair.Pair, dailyInterval)
+	if err != nil {
+		return -1, "", fmt.Errorf("cannot get the %s price history : %w", pair.Pair, err)
+	}
+
+	if len(candles) > pair.Drawdown.Days {
+		candles = candles[len(candles)-pair.Drawdown.Days:]
+	}
+
+	askPrice, err := i.tradingService.AskPrice(pair.Pair)
+	if err != nil {
+		return -1, "", fmt.Errorf("cannot get the %s ask price : %w", pair.Pair, err)
+	}
+
+	multiplier, reason := pair.Drawdown.Multiplier(domain.Highest(candles), askPrice)
+	log.Printf("[%s] Drawdown multiplier : %.2fx %s", pair.Pair, multiplier, reason)
+
+	return multiplier, reason, nil
+}
@@ -0,0 +1,578 @@
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func init() {
+	exchange.Register("kraken", func(config domain.Config) (exchange.Exchange, error) {
+		api := NewApi(config.Kraken.Key, config.Kraken.Secret)
+		if config.Kraken.Feed != nil {
+			return NewStreamingExchange(api, *config.Kraken.Feed, config.TradedPairs())
+		}
+
+		return NewExchange(api), nil
+	})
+}
+
+// krakenExchange is the Kraken adapter of the exchange abstraction
+type krakenExchange struct {
+	api ApiInterface
+	// feed streams the prices, nil to request the REST ticker
+	feed *PriceFeed
+}
+
+func NewExchange(api ApiInterface) exchange.Exchange {
+	return krakenExchange{api: api}
+}
+
+// NewStreamingExchange Get a Kraken adapter whose prices of the `pairs` are streamed from the WebSocket API of the
+// `feed`, falling back to the REST ticker when the stream is stale
+func NewStreamingExchange(api ApiInterface, feed domain.Feed, pairs []string) (exchange.Exchange, error) {
+	staleness, err := feed.StalenessDuration()
+	if err != nil {
+		return nil, fmt.Errorf("the feed staleness is invalid : %w", err)
+	}
+
+	names := map[string]string{}
+	for _, pair := range pairs {
+		assetPairs, err := api.Query("AssetPairs", map[string]string{
+			"pair": pair,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("the %s WebSocket name cannot be retrieved : %w", pair, err)
+		}
+
+		name, ok := extractData(assetPairs, pair, "wsname").(string)
+		if !ok {
+			return nil, fmt.Errorf("the %s pair has no WebSocket name", pair)
+		}
+		names[name] = pair
+	}
+
+	priceFeed := NewPriceFeed(feed.Url, names, staleness)
+	priceFeed.Start()
+
+	return krakenExchange{api: api, feed: priceFeed}, nil
+}
+
+// Balances Get the quantity held of every asset of the account, with the Kraken asset codes (e.g. XXBT, ZEUR)
+func (k krakenExchange) Balances() (map[string]float64, error) {
+	response, err := k.api.Query("Balance", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	quantities, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the balances are missing")
+	}
+
+	balances := map[string]float64{}
+	for asset, quantity := range quantities {
+		balances[asset], err = strconv.ParseFloat(fmt.Sprint(quantity), 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return balances, nil
+}
+
+// Close Stop streaming the prices, if they are
+func (k krakenExchange) Close() error {
+	if k.feed != nil {
+		k.feed.Close()
+	}
+
+	return nil
+}
+
+// Ticker Get the latest ticker information, streamed when the price feed is fresh
+func (k krakenExchange) Ticker(pair string) (exchange.Ticker, error) {
+	if k.feed != nil {
+		if ticker, ok := k.feed.Ticker(pair); ok {
+			return ticker, nil
+		}
+
+		log.Printf("The streamed %s prices are stale, requesting the ticker", pair)
+	}
+
+	response, err := k.api.Query("Ticker", map[string]string{
+		"pair": pair,
+	})
+	if err != nil {
+		return exchange.Ticker{}, err
+	}
+
+	fields, ok := extractData(response, pair).(map[string]interface{})
+	if !ok {
+		return exchange.Ticker{}, fmt.Errorf("the %s ticker is missing", pair)
+	}
+
+	ticker := exchange.Ticker{}
+	for field, price := range map[string]*float64{"a": &ticker.Ask, "b": &ticker.Bid} {
+		values, ok := fields[field].([]interface{})
+		if !ok || len(values) == 0 {
+			return exchange.Ticker{}, fmt.Errorf("the %s ticker has no %s price", pair, field)
+		}
+
+		*price, err = strconv.ParseFloat(fmt.Sprint(values[0]), 64)
+		if err != nil {
+			return exchange.Ticker{}, err
+		}
+		if *price <= 0 {
+			return exchange.Ticker{}, fmt.Errorf("the %s ticker %s price %v is invalid", pair, field, values[0])
+		}
+	}
+
+	return ticker, nil
+}
+
+// Fee Get fee percentage for the given pair
+func (k krakenExchange) Fee(pair string) (float64, error) {
+	tradeVolume, err := k.api.Query("TradeVolume", map[string]string{"pair": pair, "fee-info": "true"})
+	if err != nil {
+		return -1, err
+	}
+
+	fee, ok := extractData(tradeVolume, "fees", pair, "fee").(string)
+	if !ok {
+		return -1, fmt.Errorf("the %s fee is missing", pair)
+	}
+
+	return strconv.ParseFloat(fee, 64)
+}
+
+// Pair Get the trading rules of the pair from its AssetPairs entry
+func (k krakenExchange) Pair(pair string) (exchange.Pair, error) {
+	assetPairs, err := k.api.Query("AssetPairs", map[string]string{
+		"pair": pair,
+	})
+	if err != nil {
+		return exchange.Pair{}, err
+	}
+
+	base, baseOk := extractData(assetPairs, pair, "base").(string)
+	quote, quoteOk := extractData(assetPairs, pair, "quote").(string)
+	if !baseOk || !quoteOk {
+		return exchange.Pair{}, fmt.Errorf("the %s pair is unknown", pair)
+	}
+
+	rules := exchange.Pair{
+		Base:  base,
+		Quote: quote,
+	}
+
+	if decimals, ok := extractData(assetPairs, pair, "lot_decimals").(float64); ok {
+		rules.VolumeDecimals = int(decimals)
+	}
+
+	if minimum, ok := extractData(assetPairs, pair, "ordermin").(string); ok {
+		rules.MinVolume, err = strconv.ParseFloat(minimum, 64)
+		if err != nil {
+			return exchange.Pair{}, err
+		}
+	}
+
+	if status, ok := extractData(assetPairs, pair, "status").(string); ok {
+		rules.Status = status
+	}
+
+	return rules, nil
+}
+
+// ServerTime Get the current time of Kraken, to the second
+func (k krakenExchange) ServerTime() (time.Time, error) {
+	response, err := k.api.Query("Time", map[string]string{})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	seconds, ok := extractData(response, "unixtime").(float64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("the server time is missing")
+	}
+
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// SystemStatus Get the trading status of Kraken (online, maintenance, cancel_only or post_only)
+func (k krakenExchange) SystemStatus() (string, error) {
+	response, err := k.api.Query("SystemStatus", map[string]string{})
+	if err != nil {
+		return "", err
+	}
+
+	status, ok := extractData(response, "status").(string)
+	if !ok {
+		return "", fmt.Errorf("the system status is missing")
+	}
+
+	return status, nil
+}
+
+// Candles Get the OHLC history of the given pair, `interval` being the candle duration in minutes.
+// Kraken returns at most the last 720 candles.
+func (k krakenExchange) Candles(pair string, interval int) ([]domain.Candle, error) {
+	return k.candles(map[string]string{
+		"pair":     pair,
+		"interval": strconv.Itoa(interval),
+	})
+}
+
+// CandlesSince Get the OHLC history of the given pair started after `since`. Kraken only serves the last 720 candles,
+// whatever `since` is.
+func (k krakenExchange) CandlesSince(pair string, interval int, since time.Time) ([]domain.Candle, error) {
+	return k.candles(map[string]string{
+		"pair":     pair,
+		"interval": strconv.Itoa(interval),
+		"since":    strconv.FormatInt(since.Unix(), 10),
+	})
+}
+
+func (k krakenExchange) candles(args map[string]string) ([]domain.Candle, error) {
+	pair := args["pair"]
+	ohlc, err := k.api.Query("OHLC", args)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := extractData(ohlc, pair).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the %s candles are missing", pair)
+	}
+
+	candles := make([]domain.Candle, len(entries))
+	for index, entry := range entries {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) < 7 {
+			return nil, fmt.Errorf("the %s candle %v is malformed", pair, entry)
+		}
+
+		seconds, ok := fields[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("the %s candle %v has no time", pair, entry)
+		}
+
+		values := make([]float64, 5)
+		for field, position := range []int{1, 2, 3, 4, 6} {
+			values[field], err = parseFloat(fields[position])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		candles[index] = domain.Candle{
+			Time:   time.Unix(int64(seconds), 0),
+			Open:   values[0],
+			High:   values[1],
+			Low:    values[2],
+			Close:  values[3],
+			Volume: values[4],
+		}
+	}
+
+	return candles, nil
+}
+
+// Trades Get up to 1000 trades of the given pair following the `since` cursor
+func (k krakenExchange) Trades(pair string, since string) ([]domain.Trade, string, error) {
+	args := map[string]string{"pair": pair}
+	if since != "" {
+		args["since"] = since
+	}
+
+	result, err := k.api.Query("Trades", args)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries, ok := extractData(result, pair).([]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("the %s trades are missing", pair)
+	}
+
+	trades := make([]domain.Trade, len(entries))
+	for index, entry := range entries {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) < 4 {
+			return nil, "", fmt.Errorf("the %s trade %v is malformed", pair, entry)
+		}
+
+		values := make([]float64, 2)
+		for field := range values {
+			values[field], err = parseFloat(fields[field])
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		seconds, secondsOk := fields[2].(float64)
+		sideCode, sideOk := fields[3].(string)
+		if !secondsOk || !sideOk {
+			return nil, "", fmt.Errorf("the %s trade %v is malformed", pair, entry)
+		}
+
+		side := domain.Buy
+		if sideCode == "s" {
+			side = domain.Sell
+		}
+
+		trades[index] = domain.Trade{
+			Time:   time.UnixMicro(int64(math.Round(seconds * 1e6))),
+			Price:  values[0],
+			Volume: values[1],
+			Side:   side,
+		}
+	}
+
+	last, _ := extractData(result, "last").(string)
+
+	return trades, last, nil
+}
+
+// PlaceOrder Place a market order, the `validate` flag only checking it
+func (k krakenExchange) PlaceOrder(order exchange.Order) (string, error) {
+	args := map[string]string{
+		"validate": strconv.FormatBool(order.Validate),
+	}
+	if order.Expiry > 0 {
+		args["expiretm"] = fmt.Sprintf("+%d", int(order.Expiry.Seconds()))
+	}
+
+	response, err := k.api.AddOrder(order.Pair, order.Side, "market", fmt.Sprintf("%f", order.Volume), args)
+	if err != nil {
+		return "", err
+	}
+
+	if order.Validate {
+		return "", nil
+	}
+
+	if response == nil || len(response.TransactionIds) == 0 {
+		return "", fmt.Errorf("the %s order has no transaction id", order.Pair)
+	}
+
+	return response.TransactionIds[0], nil
+}
+
+// QueryOrder Get the order with the given transaction id. Kraken charges the fees in the quote currency, they are
+// converted to base asset units.
+func (k krakenExchange) QueryOrder(id string) (exchange.Order, error) {
+	response, err := k.api.Query("QueryOrders", map[string]string{"txid": id})
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	fields, ok := extractData(response, id).(map[string]interface{})
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("the %s order is unknown", id)
+	}
+
+	values := map[string]float64{}
+	for _, field := range []string{"vol", "vol_exec", "price", "fee"} {
+		values[field], err = strconv.ParseFloat(fmt.Sprint(fields[field]), 64)
+		if err != nil {
+			return exchange.Order{}, err
+		}
+	}
+
+	pair, pairOk := extractData(fields, "descr", "pair").(string)
+	side, sideOk := extractData(fields, "descr", "type").(string)
+	status, statusOk := fields["status"].(string)
+	if !pairOk || !sideOk || !statusOk {
+		return exchange.Order{}, fmt.Errorf("the %s order is malformed", id)
+	}
+
+	order := exchange.Order{
+		Id:     id,
+		Pair:   pair,
+		Side:   side,
+		Volume: values["vol"],
+		Status: status,
+		Price:  values["price"],
+		Filled: values["vol_exec"],
+	}
+	if order.Price > 0 {
+		order.Fee = values["fee"] / order.Price
+	}
+
+	return order, nil
+}
+
+// CancelOrder Cancel the open order with the given transaction id
+func (k krakenExchange) CancelOrder(id string) error {
+	_, err := k.api.Query("CancelOrder", map[string]string{"txid": id})
+
+	return err
+}
+
+// Ledgers Get the latest ledger entries of the given asset and type (e.g. deposit), oldest first
+func (k krakenExchange) Ledgers(asset string, ledgerType string) ([]domain.Ledger, error) {
+	response, err := k.api.Query("Ledgers", map[string]string{
+		"asset": asset,
+		"type":  ledgerType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := extractData(response, "ledger").(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the %s ledger is missing", asset)
+	}
+
+	ledgers := make([]domain.Ledger, 0, len(entries))
+	for id, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the %s ledger entry is malformed", id)
+		}
+
+		amount, err := parseFloat(fields["amount"])
+		if err != nil {
+			return nil, err
+		}
+		fee, err := parseFloat(fields["fee"])
+		if err != nil {
+			return nil, err
+		}
+
+		seconds, secondsOk := fields["time"].(float64)
+		entryType, typeOk := fields["type"].(string)
+		entryAsset, assetOk := fields["asset"].(string)
+		if !secondsOk || !typeOk || !assetOk {
+			return nil, fmt.Errorf("the %s ledger entry is malformed", id)
+		}
+
+		ledgers = append(ledgers, domain.Ledger{
+			Id:     id,
+			Time:   time.UnixMicro(int64(math.Round(seconds * 1e6))),
+			Type:   entryType,
+			Asset:  entryAsset,
+			Amount: amount,
+			Fee:    fee,
+		})
+	}
+
+	sort.Slice(ledgers, func(i, j int) bool {
+		return ledgers[i].Time.Before(ledgers[j].Time)
+	})
+
+	return ledgers, nil
+}
+
+// WithdrawalFee Get the fee of withdrawing `amount` of the asset to the withdrawal key, checking that the key exists
+// and that the amount is within the withdrawal limit without withdrawing anything
+func (k krakenExchange) WithdrawalFee(asset string, key string, amount float64) (float64, error) {
+	info, err := k.api.Query("WithdrawInfo", map[string]string{
+		"asset":  asset,
+		"key":    key,
+		"amount": strconv.FormatFloat(amount, 'f', -1, 64),
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	limit, err := parseFloat(extractData(info, "limit"))
+	if err != nil {
+		return -1, fmt.Errorf("the %s withdrawal limit is invalid : %w", asset, err)
+	}
+
+	if amount > limit {
+		return -1, fmt.Errorf("the %f %s withdrawal exceeds the %f limit", amount, asset, limit)
+	}
+
+	fee, err := parseFloat(extractData(info, "fee"))
+	if err != nil {
+		return -1, fmt.Errorf("the %s withdrawal fee is invalid : %w", asset, err)
+	}
+
+	return fee, nil
+}
+
+// Withdraw Withdraw `amount` of the asset to the withdrawal key and get the withdrawal reference id
+func (k krakenExchange) Withdraw(asset string, key string, amount float64) (string, error) {
+	withdrawal, err := k.api.Query("Withdraw", map[string]string{
+		"asset":  asset,
+		"key":    key,
+		"amount": strconv.FormatFloat(amount, 'f', -1, 64),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	reference, ok := extractData(withdrawal, "refid").(string)
+	if !ok {
+		return "", fmt.Errorf("the %s withdrawal has no reference", asset)
+	}
+
+	return reference, nil
+}
+
+// Stake Allocate `amount` of the asset to the given Kraken Earn strategy, or to the first strategy offered for the
+// asset if `strategy` is empty, and get the id of the strategy
+func (k krakenExchange) Stake(asset string, amount float64, strategy string) (string, error) {
+	if strategy == "" {
+		strategies, err := k.api.Query("Earn/Strategies", map[string]string{"asset": asset})
+		if err != nil {
+			return "", err
+		}
+
+		items, _ := extractData(strategies, "items").([]interface{})
+		if len(items) == 0 {
+			return "", fmt.Errorf("no earn strategy is offered for %s", asset)
+		}
+
+		id, ok := extractData(items[0], "id").(string)
+		if !ok {
+			return "", fmt.Errorf("the %s earn strategy has no id", asset)
+		}
+		strategy = id
+	}
+
+	_, err := k.api.Query("Earn/Allocate", map[string]string{
+		"amount":      strconv.FormatFloat(amount, 'f', -1, 64),
+		"strategy_id": strategy,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strategy, nil
+}
+
+// extractData Get the value of the response at the path of fields, nil when it's missing
+func extractData(data interface{}, fields ...string) interface{} {
+	fieldData := data
+	for _, field := range fields {
+		values, ok := fieldData.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fieldData = values[field]
+	}
+
+	return fieldData
+}
+
+// parseFloat Get the number of a response value, Kraken sending most of them as strings
+func parseFloat(value interface{}) (float64, error) {
+	switch number := value.(type) {
+	case string:
+		return strconv.ParseFloat(number, 64)
+	case float64:
+		return number, nil
+	}
+
+	return 0, fmt.Errorf("the %v value is not a number", value)
+}
@@ -0,0 +1,234 @@
+package kraken
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/mocks"
+	"strings"
+	"testing"
+)
+
+func TestExchangePair(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi)
+
+	krakenApi.EXPECT().Query("AssetPairs", map[string]string{"pair": "XXBTZEUR"}).Return(map[string]interface{}{
+		"XXBTZEUR": map[string]interface{}{"base": "XXBT", "quote": "ZEUR", "lot_decimals": float64(8), "ordermin": "0.0001"},
+	}, nil)
+
+	pair, err := krakenExchange.Pair("XXBTZEUR")
+	if err != nil || pair != (exchange.Pair{Base: "XXBT", Quote: "ZEUR", MinVolume: 0.0001, VolumeDecimals: 8}) {
+		t.Errorf("The pair rules are %v (%v)", pair, err)
+	}
+}
+
+func TestExchangeTickerInvalid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi)
+
+	responses := []map[string]interface{}{
+		{},
+		{"XXBTZEUR": map[string]interface{}{"a": []interface{}{"20000.0", "1", "1.000"}}},
+		{"XXBTZEUR": map[string]interface{}{"a": []interface{}{"20000.0", "1", "1.000"}, "b": []interface{}{"unknown"}}},
+		{"XXBTZEUR": map[string]interface{}{"a": []interface{}{"0", "1", "1.000"}, "b": []interface{}{"19990.0", "1", "1.000"}}},
+	}
+	for _, response := range responses {
+		krakenApi.EXPECT().Query("Ticker", map[string]string{"pair": "XXBTZEUR"}).Return(response, nil)
+
+		if ticker, err := krakenExchange.Ticker("XXBTZEUR"); err == nil {
+			t.Errorf("The %v ticker should be rejected : %v", response, ticker)
+		}
+	}
+}
+
+func TestExchangePairMalformed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi)
+
+	responses := []interface{}{
+		nil,
+		map[string]interface{}{},
+		map[string]interface{}{"XXBTZEUR": "unknown"},
+		map[string]interface{}{"XXBTZEUR": map[string]interface{}{"base": "XXBT", "quote": 1.0}},
+	}
+	for _, response := range responses {
+		krakenApi.EXPECT().Query("AssetPairs", map[string]string{"pair": "XXBTZEUR"}).Return(response, nil)
+
+		if rules, err := krakenExchange.Pair("XXBTZEUR"); err == nil {
+			t.Errorf("The %v pair should be rejected : %v", response, rules)
+		}
+	}
+}
+
+func TestExchangeLedgersMalformed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi).(exchange.Funding)
+
+	entry := func(fields map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"ledger": map[string]interface{}{"L1": fields}}
+	}
+	responses := []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"ledger": []interface{}{}},
+		entry(nil),
+		entry(map[string]interface{}{"amount": "500.0", "fee": "0.0", "time": "1665400000", "type": "deposit", "asset": "ZEUR"}),
+		entry(map[string]interface{}{"amount": "500.0", "time": 1665400000.0, "type": "deposit", "asset": "ZEUR"}),
+		entry(map[string]interface{}{"amount": "500.0", "fee": "0.0", "time": 1665400000.0, "type": "deposit"}),
+	}
+	for _, response := range responses {
+		krakenApi.EXPECT().Query("Ledgers", map[string]string{"asset": "ZEUR", "type": "deposit"}).Return(response, nil)
+
+		if ledgers, err := krakenExchange.Ledgers("ZEUR", "deposit"); err == nil {
+			t.Errorf("The %v ledger should be rejected : %v", response, ledgers)
+		}
+	}
+}
+
+func TestExchangeCandlesMalformed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi)
+
+	responses := []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"XXBTZEUR": []interface{}{[]interface{}{1665400000.0, "1"}}},
+		map[string]interface{}{"XXBTZEUR": []interface{}{[]interface{}{"1665400000", "1", "1", "1", "1", "1", "1", 1.0}}},
+	}
+	for _, response := range responses {
+		krakenApi.EXPECT().Query("OHLC", map[string]string{"pair": "XXBTZEUR", "interval": "1440"}).Return(response, nil)
+
+		if candles, err := krakenExchange.Candles("XXBTZEUR", 1440); err == nil {
+			t.Errorf("The %v candles should be rejected : %v", response, candles)
+		}
+	}
+}
+
+func TestExchangeQueryOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi)
+
+	krakenApi.EXPECT().Query("QueryOrders", map[string]string{"txid": "OID"}).Return(map[string]interface{}{
+		"OID": map[string]interface{}{
+			"status":   "closed",
+			"descr":    map[string]interface{}{"pair": "XXBTZEUR", "type": "buy"},
+			"vol":      "0.01000000",
+			"vol_exec": "0.01000000",
+			"price":    "20000.0",
+			"fee":      "0.5",
+		},
+	}, nil)
+	krakenApi.EXPECT().Query("QueryOrders", map[string]string{"txid": "UNKNOWN"}).Return(map[string]interface{}{}, nil)
+
+	order, err := krakenExchange.QueryOrder("OID")
+	expected := exchange.Order{Id: "OID", Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 0.01, Status: exchange.Closed, Price: 20000, Filled: 0.01, Fee: 0.000025}
+	if err != nil || order != expected {
+		t.Errorf("The order is %v (%v)", order, err)
+	}
+
+	_, err = krakenExchange.QueryOrder("UNKNOWN")
+	if err == nil || err.Error() != "the UNKNOWN order is unknown" {
+		t.Errorf("No relevant error was returned : %v", err)
+	}
+}
+
+func TestExchangeCancelOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	krakenApi := mocks.NewMockApiInterface(controller)
+	krakenExchange := NewExchange(krakenApi)
+
+	krakenApi.EXPECT().Query("CancelOrder", map[string]string{"txid": "OID"}).Return(nil, errors.New("cancel error"))
+
+	err := krakenExchange.CancelOrder("OID")
+	if err == nil || err.Error() != "cancel error" {
+		t.Errorf("No relevant error was returned : %v", err)
+	}
+}
+
+func TestAccountUnsupportedFeatures(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := NewAccount(mocks.NewMockExchange(controller))
+
+	if _, err := accountService.Ledgers("ZEUR", "deposit"); err != exchange.ErrUnsupported {
+		t.Errorf("The ledgers should not be supported : %v", err)
+	}
+
+	if _, err := accountService.Stake("DOT", 1, ""); err != exchange.ErrUnsupported {
+		t.Errorf("The staking should not be supported : %v", err)
+	}
+
+	if _, err := accountService.Withdraw("XXBT", "ledger", 1); err != exchange.ErrUnsupported {
+		t.Errorf("The withdrawals should not be supported : %v", err)
+	}
+}
+
+func TestExchangeFakeServer(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Balances = map[string]float64{"ZEUR": 1000}
+	server.Pairs = map[string]*fake.Pair{
+		"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR", Ask: 20000, Bid: 19990, Fee: 0.25, LotDecimals: 8, OrderMin: 0.0001},
+	}
+
+	krakenExchange := NewExchange(NewApiWithClient("key", "c2VjcmV0", server.Client()))
+
+	ticker, err := krakenExchange.Ticker("XXBTZEUR")
+	if err != nil || ticker != (exchange.Ticker{Ask: 20000, Bid: 19990}) {
+		t.Errorf("The ticker is %v (%v)", ticker, err)
+	}
+
+	fee, err := krakenExchange.Fee("XXBTZEUR")
+	if err != nil || fee != 0.25 {
+		t.Errorf("The fee is %v (%v)", fee, err)
+	}
+
+	id, err := krakenExchange.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 0.01})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	order, err := krakenExchange.QueryOrder(id)
+	if err != nil || order.Status != exchange.Closed || order.Filled != 0.01 || order.Price != 20000 || order.Fee != 0.000025 {
+		t.Errorf("The order is %v (%v)", order, err)
+	}
+
+	balances, err := krakenExchange.Balances()
+	if err != nil || balances["ZEUR"] != 799.5 || balances["XXBT"] != 0.01 {
+		t.Errorf("The balances are %v (%v)", balances, err)
+	}
+
+	_, err = krakenExchange.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 1})
+	if err == nil || !strings.Contains(err.Error(), "EOrder:Insufficient funds") {
+		t.Errorf("No insufficient funds error was returned : %v", err)
+	}
+
+	server.Lock()
+	server.RateLimited = true
+	server.Unlock()
+	_, err = krakenExchange.Balances()
+	if err == nil || !strings.Contains(err.Error(), "EAPI:Rate limit exceeded") {
+		t.Errorf("No rate limit error was returned : %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"kraken-dca-bot/internal/exchange"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// bookDepth is the number of price levels of the book subscription
+const bookDepth = 10
+
+// Reconnection delays, doubled after every failed connection
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// PriceFeed keeps the best ask and bid prices of pairs up to date from the ticker and book channels of the Kraken
+// WebSocket API, reconnecting whenever the connection is lost or silent for the staleness duration. The prices of a
+// pair are stale when they weren't updated for the staleness duration, whatever the heartbeats.
+type PriceFeed struct {
+	url string
+	// pairs are the streamed pairs by WebSocket name (e.g. XXBTZEUR for XBT/EUR)
+	pairs     map[string]string
+	staleness time.Duration
+	// reconnectDelay is the delay before the first reconnection attempt
+	reconnectDelay time.Duration
+	now            func() time.Time
+
+	mutex   sync.Mutex
+	tickers map[string]exchange.Ticker
+	// updates are the last update time of the pair prices
+	updates    map[string]time.Time
+	books      map[string]*book
+	connection *websocket.Conn
+	done       chan struct{}
+	closing    sync.Once
+	stopped    sync.WaitGroup
+}
+
+// book is the best price levels of the sides of a pair book, volumes by price
+type book struct {
+	asks map[float64]float64
+	bids map[float64]float64
+}
+
+// NewPriceFeed Get a feed streaming the prices of the `pairs`, by WebSocket name, from the `url` WebSocket API.
+// The streaming starts with Start.
+func NewPriceFeed(url string, pairs map[string]string, staleness time.Duration) *PriceFeed {
+	return &PriceFeed{
+		url:            url,
+		pairs:          pairs,
+		staleness:      staleness,
+		reconnectDelay: minReconnectDelay,
+		now:            time.Now,
+		tickers:        map[string]exchange.Ticker{},
+		updates:        map[string]time.Time{},
+		books:          map[string]*book{},
+		done:           make(chan struct{}),
+	}
+}
+
+// Start Stream the prices in the background until Close is called
+func (f *PriceFeed) Start() {
+	f.stopped.Add(1)
+	go func() {
+		defer f.stopped.Done()
+		f.run()
+	}()
+}
+
+// Close Stop streaming the prices
+func (f *PriceFeed) Close() {
+	f.closing.Do(func() {
+		f.mutex.Lock()
+		close(f.done)
+		if f.connection != nil {
+			_ = f.connection.Close()
+		}
+		f.mutex.Unlock()
+	})
+
+	f.stopped.Wait()
+}
+
+// Ticker Get the streamed prices of the pair, false when none were received on the current connection or they are
+// stale
+func (f *PriceFeed) Ticker(pair string) (exchange.Ticker, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ticker, ok := f.tickers[pair]
+	if !ok || f.connection == nil || f.now().Sub(f.updates[pair]) > f.staleness {
+		return exchange.Ticker{}, false
+	}
+
+	return ticker, true
+}
+
+// run Stream the prices, reconnecting with an increasing delay until the feed is closed
+func (f *PriceFeed) run() {
+	delay := f.reconnectDelay
+	for {
+		connected, err := f.stream()
+		if connected {
+			delay = f.reconnectDelay
+		}
+
+		select {
+		case <-f.done:
+			return
+		default:
+		}
+
+		log.Printf("The price feed was disconnected, reconnecting in %s : %v", delay, err)
+		select {
+		case <-f.done:
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// stream Connect, subscribe to the ticker and book channels and handle the messages until the connection fails.
+// Get whether the connection was established.
+func (f *PriceFeed) stream() (bool, error) {
+	connection, _, err := websocket.DefaultDialer.Dial(f.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("the price feed cannot connect : %w", err)
+	}
+
+	f.mutex.Lock()
+	select {
+	case <-f.done:
+		f.mutex.Unlock()
+		_ = connection.Close()
+
+		return true, nil
+	default:
+	}
+	f.connection = connection
+	f.reset()
+	f.mutex.Unlock()
+
+	defer func() {
+		f.mutex.Lock()
+		f.connection = nil
+		f.mutex.Unlock()
+		_ = connection.Close()
+	}()
+
+	names := make([]string, 0, len(f.pairs))
+	for name := range f.pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, subscription := range []map[string]interface{}{{"name": "ticker"}, {"name": "book", "depth": bookDepth}} {
+		err = connection.WriteJSON(map[string]interface{}{
+			"event":        "subscribe",
+			"pair":         names,
+			"subscription": subscription,
+		})
+		if err != nil {
+			return true, fmt.Errorf("the price feed cannot subscribe : %w", err)
+		}
+	}
+
+	for {
+		err = connection.SetReadDeadline(time.Now().Add(f.staleness))
+		if err != nil {
+			return true, err
+		}
+
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			return true, fmt.Errorf("the price feed cannot be read : %w", err)
+		}
+
+		err = f.handle(message)
+		if err != nil {
+			log.Printf("The price feed message cannot be handled : %v", err)
+		}
+	}
+}
+
+// reset Forget the prices of the previous connection, the pairs whose subscription fails being never served
+func (f *PriceFeed) reset() {
+	f.tickers = map[string]exchange.Ticker{}
+	f.updates = map[string]time.Time{}
+	f.books = map[string]*book{}
+}
+
+// handle Update the prices with a channel message, the event messages (e.g. heartbeats) being ignored
+func (f *PriceFeed) handle(message []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var fields []json.RawMessage
+	if json.Unmarshal(message, &fields) != nil || len(fields) < 4 {
+		return nil
+	}
+
+	var channel, name string
+	err := json.Unmarshal(fields[len(fields)-2], &channel)
+	if err != nil {
+		return fmt.Errorf("the channel name is invalid : %w", err)
+	}
+	err = json.Unmarshal(fields[len(fields)-1], &name)
+	if err != nil {
+		return fmt.Errorf("the pair name is invalid : %w", err)
+	}
+
+	pair, ok := f.pairs[name]
+	if !ok {
+		return fmt.Errorf("the %s pair is not streamed", name)
+	}
+
+	payloads := make([]map[string]json.RawMessage, len(fields)-3)
+	for index := range payloads {
+		err = json.Unmarshal(fields[index+1], &payloads[index])
+		if err != nil {
+			return fmt.Errorf("the %s %s payload is invalid : %w", name, channel, err)
+		}
+	}
+
+	if channel == "ticker" {
+		return f.handleTicker(pair, payloads[0])
+	}
+
+	return f.handleBook(pair, payloads)
+}
+
+// handleTicker Update the pair prices with the best ask and bid of a ticker message
+func (f *PriceFeed) handleTicker(pair string, payload map[string]json.RawMessage) error {
+	var ticker exchange.Ticker
+	for field, price := range map[string]*float64{"a": &ticker.Ask, "b": &ticker.Bid} {
+		var values []interface{}
+		err := json.Unmarshal(payload[field], &values)
+		if err != nil || len(values) == 0 {
+			return fmt.Errorf("the %s ticker has no %s price", pair, field)
+		}
+
+		*price, err = strconv.ParseFloat(fmt.Sprint(values[0]), 64)
+		if err != nil {
+			return fmt.Errorf("the %s ticker %s price is invalid : %w", pair, field, err)
+		}
+	}
+
+	f.tickers[pair] = ticker
+	f.updates[pair] = f.now()
+
+	return nil
+}
+
+// handleBook Apply a book snapshot or update, then update the pair prices with the best levels
+func (f *PriceFeed) handleBook(pair string, payloads []map[string]json.RawMessage) error {
+	pairBook, ok := f.books[pair]
+	if !ok {
+		pairBook = &book{asks: map[float64]float64{}, bids: map[float64]float64{}}
+		f.books[pair] = pairBook
+	}
+
+	for _, payload := range payloads {
+		for field, side := range map[string]map[float64]float64{"as": pairBook.asks, "a": pairBook.asks, "bs": pairBook.bids, "b": pairBook.bids} {
+			raw, ok := payload[field]
+			if !ok {
+				continue
+			}
+
+			var levels [][]interface{}
+			err := json.Unmarshal(raw, &levels)
+			if err != nil {
+				return fmt.Errorf("the %s book levels are invalid : %w", pair, err)
+			}
+
+			for _, level := range levels {
+				if len(level) < 2 {
+					return fmt.Errorf("the %s book level %v is invalid", pair, level)
+				}
+
+				price, err := strconv.ParseFloat(fmt.Sprint(level[0]), 64)
+				if err != nil {
+					return fmt.Errorf("the %s book price is invalid : %w", pair, err)
+				}
+				volume, err := strconv.ParseFloat(fmt.Sprint(level[1]), 64)
+				if err != nil {
+					return fmt.Errorf("the %s book volume is invalid : %w", pair, err)
+				}
+
+				if volume == 0 {
+					delete(side, price)
+				} else {
+					side[price] = volume
+				}
+			}
+		}
+	}
+
+	asks := truncate(pairBook.asks, func(i, j float64) bool { return i < j })
+	bids := truncate(pairBook.bids, func(i, j float64) bool { return i > j })
+	if len(asks) > 0 && len(bids) > 0 {
+		f.tickers[pair] = exchange.Ticker{Ask: asks[0], Bid: bids[0]}
+		f.updates[pair] = f.now()
+	}
+
+	return nil
+}
+
+// truncate Remove the levels of a book side beyond the subscription depth, and get its prices from the best one
+func truncate(side map[float64]float64, better func(i, j float64) bool) []float64 {
+	prices := make([]float64, 0, len(side))
+	for price := range side {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return better(prices[i], prices[j])
+	})
+
+	if len(prices) > bookDepth {
+		for _, price := range prices[bookDepth:] {
+			delete(side, price)
+		}
+		prices = prices[:bookDepth]
+	}
+
+	return prices
+}
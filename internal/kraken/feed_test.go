@@ -0,0 +1,181 @@
+package kraken
+
+import (
+	"github.com/gorilla/websocket"
+	"io"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStreamingExchange(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Pairs = map[string]*fake.Pair{
+		"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR", Ask: 20000, Bid: 19990, WsName: "XBT/EUR"},
+	}
+
+	streamingExchange, err := NewStreamingExchange(
+		NewApiWithClient("key", "c2VjcmV0", server.Client()),
+		domain.Feed{Url: server.WebSocketURL(), Staleness: "5s"},
+		[]string{"XXBTZEUR"},
+	)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+	feed := streamingExchange.(krakenExchange).feed
+	defer streamingExchange.(io.Closer).Close()
+
+	waitForTicker(t, feed, "XXBTZEUR", exchange.Ticker{Ask: 20000, Bid: 19990})
+
+	server.SetPrices("XXBTZEUR", 21000, 20990)
+	waitForTicker(t, feed, "XXBTZEUR", exchange.Ticker{Ask: 21000, Bid: 20990})
+
+	server.Fail("Ticker", "EService:Unavailable")
+	ticker, err := streamingExchange.Ticker("XXBTZEUR")
+	if err != nil || ticker != (exchange.Ticker{Ask: 21000, Bid: 20990}) {
+		t.Errorf("The streamed ticker is %v (%v)", ticker, err)
+	}
+	server.Fail("Ticker", "")
+
+	server.Lock()
+	server.Pairs["XXBTZEUR"].Ask = 22000
+	server.Pairs["XXBTZEUR"].Bid = 21990
+	server.Unlock()
+	feed.mutex.Lock()
+	feed.now = func() time.Time { return time.Now().Add(time.Minute) }
+	feed.mutex.Unlock()
+
+	ticker, err = streamingExchange.Ticker("XXBTZEUR")
+	if err != nil || ticker != (exchange.Ticker{Ask: 22000, Bid: 21990}) {
+		t.Errorf("The stale stream should fall back to the REST ticker : %v (%v)", ticker, err)
+	}
+}
+
+func TestStreamingExchangeUnknownPair(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	_, err := NewStreamingExchange(
+		NewApiWithClient("key", "c2VjcmV0", server.Client()),
+		domain.Feed{Url: server.WebSocketURL(), Staleness: "5s"},
+		[]string{"XXBTZEUR"},
+	)
+	if err == nil {
+		t.Error("The unknown pair should not be streamed")
+	}
+}
+
+func TestPriceFeedReconnection(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Pairs = map[string]*fake.Pair{
+		"XETHZEUR": {Base: "XETH", Quote: "ZEUR", Ask: 1500, Bid: 1499, WsName: "ETH/EUR"},
+	}
+
+	feed := NewPriceFeed(server.WebSocketURL(), map[string]string{"ETH/EUR": "XETHZEUR"}, 5*time.Second)
+	feed.reconnectDelay = 10 * time.Millisecond
+	feed.Start()
+	defer feed.Close()
+
+	waitForTicker(t, feed, "XETHZEUR", exchange.Ticker{Ask: 1500, Bid: 1499})
+
+	server.Disconnect()
+	server.SetPrices("XETHZEUR", 1600, 1599)
+
+	waitForTicker(t, feed, "XETHZEUR", exchange.Ticker{Ask: 1600, Bid: 1599})
+}
+
+func TestPriceFeedBook(t *testing.T) {
+	feed := NewPriceFeed("", map[string]string{"XBT/EUR": "XXBTZEUR"}, time.Minute)
+
+	messages := []string{
+		`{"event":"heartbeat"}`,
+		`[336,{"as":[["20000.0","1.0","1"],["20010.0","2.0","1"]],"bs":[["19990.0","1.0","1"],["19980.0","3.0","1"]]},"book-10","XBT/EUR"]`,
+		`[336,{"a":[["20000.0","0.00000000","2"]]},{"b":[["19995.0","0.5","2"]]},"book-10","XBT/EUR"]`,
+	}
+	for _, message := range messages {
+		if err := feed.handle([]byte(message)); err != nil {
+			t.Fatalf("An unexpected error occurred : %v", err)
+		}
+	}
+
+	if ticker := feed.tickers["XXBTZEUR"]; ticker != (exchange.Ticker{Ask: 20010, Bid: 19995}) {
+		t.Errorf("The book ticker is %v", ticker)
+	}
+
+	for index := 0; index < 2*bookDepth; index++ {
+		message := `[336,{"b":[["` + strconv.Itoa(19000+index) + `","1.0","3"]]},"book-10","XBT/EUR"]`
+		if err := feed.handle([]byte(message)); err != nil {
+			t.Fatalf("An unexpected error occurred : %v", err)
+		}
+	}
+	if len(feed.books["XXBTZEUR"].bids) != bookDepth {
+		t.Errorf("The book has %d bids", len(feed.books["XXBTZEUR"].bids))
+	}
+
+	if err := feed.handle([]byte(`[12,{"a":["1","1","1"]},"ticker","ETH/EUR"]`)); err == nil {
+		t.Error("The message of an unknown pair should be rejected")
+	}
+
+	if _, ok := feed.Ticker("XXBTZEUR"); ok {
+		t.Error("The prices of a disconnected feed should be stale")
+	}
+}
+
+// waitForTicker Wait for the feed to stream the expected prices of the pair
+func waitForTicker(t *testing.T, feed *PriceFeed, pair string, expected exchange.Ticker) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if ticker, ok := feed.Ticker(pair); ok && ticker == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ticker, ok := feed.Ticker(pair)
+	t.Fatalf("The streamed %s ticker is %v (%v) instead of %v", pair, ticker, ok, expected)
+}
+
+func TestPriceFeedPairStaleness(t *testing.T) {
+	feed := NewPriceFeed("", map[string]string{"XBT/EUR": "XXBTZEUR", "ETH/EUR": "XETHZEUR"}, 10*time.Second)
+	feed.connection = &websocket.Conn{}
+	start := time.Now()
+	feed.now = func() time.Time { return start }
+
+	for _, message := range []string{
+		`[1,{"a":["20000.0",1,"1.0"],"b":["19990.0",1,"1.0"]},"ticker","XBT/EUR"]`,
+		`[2,{"a":["1500.0",1,"1.0"],"b":["1499.0",1,"1.0"]},"ticker","ETH/EUR"]`,
+	} {
+		if err := feed.handle([]byte(message)); err != nil {
+			t.Fatalf("An unexpected error occurred : %v", err)
+		}
+	}
+
+	// Only XBT/EUR keeps being updated, the heartbeats don't refresh the ETH/EUR prices
+	feed.now = func() time.Time { return start.Add(15 * time.Second) }
+	for _, message := range []string{`{"event":"heartbeat"}`, `[1,{"a":["20100.0",1,"1.0"],"b":["20090.0",1,"1.0"]},"ticker","XBT/EUR"]`} {
+		if err := feed.handle([]byte(message)); err != nil {
+			t.Fatalf("An unexpected error occurred : %v", err)
+		}
+	}
+
+	if ticker, ok := feed.Ticker("XXBTZEUR"); !ok || ticker != (exchange.Ticker{Ask: 20100, Bid: 20090}) {
+		t.Errorf("The XXBTZEUR ticker is %v (%v)", ticker, ok)
+	}
+
+	if _, ok := feed.Ticker("XETHZEUR"); ok {
+		t.Error("The XETHZEUR prices should be stale")
+	}
+
+	// A new connection doesn't serve the prices of the previous one
+	feed.reset()
+	if _, ok := feed.Ticker("XXBTZEUR"); ok {
+		t.Error("The prices of the previous connection should be forgotten")
+	}
+}
@@ -0,0 +1,47 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// goalAmount Get the amount needed this round for the pair to reach its accumulation goal by the deadline.
+// The goal progress is recorded on the transaction.
+func (i investingService) goalAmount(pair domain.DCAPair, transaction *domain.Transaction) (float64, error) {
+	period, err := i.config.Period()
+	if err != nil {
+		return -1, fmt.Errorf("cannot parse the DCA frequency : %w", err)
+	}
+
+	asset, err := i.tradingService.BaseAsset(pair.Pair)
+	if err != nil {
+		return -1, fmt.Errorf("cannot get the %s base asset : %w", pair.Pair, err)
+	}
+
+	holdings, err := i.accountService.Holdings(asset)
+	if err != nil {
+		return -1, fmt.Errorf("cannot get the %s holdings : %w", asset, err)
+	}
+
+	askPrice, err := i.tradingService.AskPrice(pair.Pair)
+	if err != nil {
+		return -1, fmt.Errorf("cannot get the %s ask price : %w", pair.Pair, err)
+	}
+
+	transaction.Goal = &domain.GoalProgress{
+		Held:     holdings,
+		Target:   pair.Goal.Quantity,
+		Deadline: pair.Goal.Deadline,
+	}
+
+	now := i.now()
+	amount := pair.Goal.Amount(holdings, askPrice, now, period)
+	log.Printf("[%s] Goal : %f/%f %s held (%.1f%%), %d rounds left - Amount : %.2f€", pair.Pair, holdings, pair.Goal.Quantity, asset, transaction.Goal.Percentage(), pair.Goal.RoundsLeft(now, period), amount)
+
+	if amount <= 0 {
+		return 0, skipError{reason: fmt.Sprintf("the %f %s goal is reached", pair.Goal.Quantity, asset)}
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,62 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/indicator"
+	"log"
+	"strings"
+)
+
+// indicatorsMultiplier Get the multiplier to apply to the pair amount given its configured technical indicators,
+// computed from its daily price history
+func (i investingService) indicatorsMultiplier(pair domain.DCAPair) (float64, string, error) {
+	candles, err := i.tradingService.Candles(pair.Pair, dailyInterval)
+	if err != nil {
+		return -1, "", fmt.Errorf("cannot get the %s price history : %w", pair.Pair, err)
+	}
+
+	askPrice, err := i.tradingService.AskPrice(pair.Pair)
+	if err != nil {
+		return -1, "", fmt.Errorf("cannot get the %s ask price : %w", pair.Pair, err)
+	}
+
+	multiplier := 1.0
+	var reasons []string
+	apply := func(rule domain.IndicatorRule, value float64, description string) {
+		ruleMultiplier := rule.Multiplier(value)
+		multiplier *= ruleMultiplier
+		reasons = append(reasons, fmt.Sprintf("%s (%.2fx)", description, ruleMultiplier))
+	}
+
+	if rule := pair.Indicators.MovingAverage; rule != nil {
+		movingAverage, err := indicator.SMA(candles, rule.Period)
+		if err != nil {
+			return -1, "", err
+		}
+		distance := (askPrice - movingAverage) / movingAverage * 100
+		apply(*rule, distance, fmt.Sprintf("%d-day MA distance %.1f%%", rule.Period, distance))
+	}
+
+	if rule := pair.Indicators.RSI; rule != nil {
+		rsi, err := indicator.RSI(candles, rule.Period)
+		if err != nil {
+			return -1, "", err
+		}
+		apply(*rule, rsi, fmt.Sprintf("%d-day RSI %.1f", rule.Period, rsi))
+	}
+
+	if rule := pair.Indicators.Volatility; rule != nil {
+		atr, err := indicator.ATR(candles, rule.Period)
+		if err != nil {
+			return -1, "", err
+		}
+		volatility := atr / askPrice * 100
+		apply(*rule, volatility, fmt.Sprintf("%d-day ATR %.1f%%", rule.Period, volatility))
+	}
+
+	reason := strings.Join(reasons, ", ")
+	log.Printf("[%s] Indicators multiplier : %.2fx - %s", pair.Pair, multiplier, reason)
+
+	return multiplier, reason, nil
+}
@@ -8,12 +8,15 @@ import (
 	"fmt"
 	"kraken-dca-bot/internal/domain"
 	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/storage"
 	"log"
+	"math"
 	"time"
 )
 
 type Investor interface {
 	Invest() []*domain.Transaction
+	InvestAmounts(amounts map[string]float64) []*domain.Transaction
 }
 
 type investingService struct {
@@ -21,25 +24,73 @@ type investingService struct {
 	accountService Account
 	tradingService Trader
 	notifier       notify.Notifier
+	history        storage.History
+	state          storage.State
+	// now is the clock the rounds are dated with
+	now func() time.Time
 }
 
-func NewInvestingService(config domain.Config, accountService Account, tradingService Trader, notifier notify.Notifier) Investor {
+func NewInvestingService(config domain.Config, accountService Account, tradingService Trader, notifier notify.Notifier, history storage.History, state storage.State) Investor {
+	return NewInvestingServiceWithClock(config, accountService, tradingService, notifier, history, state, time.Now)
+}
+
+// NewInvestingServiceWithClock Get an investing service dating its rounds and transactions with the `now` clock,
+// e.g. to replay a strategy over historical data
+func NewInvestingServiceWithClock(config domain.Config, accountService Account, tradingService Trader, notifier notify.Notifier, history storage.History, state storage.State, now func() time.Time) Investor {
 	return investingService{
 		config:         config,
 		accountService: accountService,
 		tradingService: tradingService,
 		notifier:       notifier,
+		history:        history,
+		state:          state,
+		now:            now,
 	}
 }
 
 func (i investingService) Invest() []*domain.Transaction {
 	start := time.Now()
-	transactions := make([]*domain.Transaction, len(i.config.Pairs))
+	transactions := make([]*domain.Transaction, 0, len(i.config.Pairs))
+
+	round := i.newRound(i.now())
 
-	for index, pair := range i.config.Pairs {
+	for _, pair := range i.config.Pairs {
 		log.Printf("Trading %s...", pair.Pair)
 
-		transactions[index] = i.investInPair(pair)
+		transactions = append(transactions, i.buyPair(pair, round)...)
+	}
+
+	for _, pair := range i.config.Pairs {
+		if pair.TakeProfit != nil {
+			transactions = append(transactions, i.takeProfit(pair)...)
+		}
+	}
+
+	for _, sell := range i.config.Sells {
+		log.Printf("Selling %s...", sell.Pair)
+
+		transactions = append(transactions, i.sellPair(sell))
+	}
+
+	log.Printf("Execution time : %s", time.Since(start))
+
+	return transactions
+}
+
+// InvestAmounts Invest the given amounts in their pairs, in the configuration order.
+// The pair strategies and multipliers are bypassed, the spending caps still being enforced.
+func (i investingService) InvestAmounts(amounts map[string]float64) []*domain.Transaction {
+	start := time.Now()
+	round := round{start: i.now(), amounts: amounts}
+
+	var transactions []*domain.Transaction
+	for _, pair := range i.config.Pairs {
+		if _, ok := amounts[pair.Pair]; !ok {
+			continue
+		}
+
+		log.Printf("Trading %s...", pair.Pair)
+		transactions = append(transactions, i.buyPair(pair, round)...)
 	}
 
 	log.Printf("Execution time : %s", time.Since(start))
@@ -47,8 +98,19 @@ func (i investingService) Invest() []*domain.Transaction {
 	return transactions
 }
 
-func (i investingService) investInPair(pair domain.DCAPair) *domain.Transaction {
+// buyPair Invest in the pair, then stake the purchase when the pair is configured to
+func (i investingService) buyPair(pair domain.DCAPair, round round) []*domain.Transaction {
+	purchase := i.investInPair(pair, round)
+	if stake := i.stakePurchase(pair, purchase); stake != nil {
+		return []*domain.Transaction{purchase, stake}
+	}
+
+	return []*domain.Transaction{purchase}
+}
+
+func (i investingService) investInPair(pair domain.DCAPair, round round) *domain.Transaction {
 	transaction := domain.NewTransaction(pair.Pair)
+	transaction.Date = i.now()
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, "transaction", transaction)
 
@@ -59,6 +121,28 @@ func (i investingService) investInPair(pair domain.DCAPair) *domain.Transaction
 		}
 	}()
 
+	var rollover *float64
+	pair.Amount, rollover, err = i.amount(pair, transaction, round)
+	var skip skipError
+	if errors.As(err, &skip) {
+		err = nil
+		transaction.Skip(skip.reason)
+		i.saveRollover(pair.Pair, rollover)
+		log.Println(transaction)
+
+		return transaction
+	}
+	if err != nil {
+		err = fmt.Errorf("the %s amount cannot be computed : %w", pair.Pair, err)
+
+		return transaction
+	}
+
+	for _, share := range round.deploymentShares[pair.Pair] {
+		log.Printf("[%s] Deploying %.2f€ from the %s plan", pair.Pair, share.amount, share.plan)
+		pair.Amount += share.amount
+	}
+
 	accountBalance, err := i.accountService.Balance(i.config.Currency)
 	if err != nil {
 		err = fmt.Errorf("account balance cannot be collected : %w", err)
@@ -73,6 +157,13 @@ func (i investingService) investInPair(pair domain.DCAPair) *domain.Transaction
 		return transaction
 	}
 
+	err = i.checkCaps(pair)
+	if err != nil {
+		err = fmt.Errorf("the %s purchase was blocked : %w", pair.Pair, err)
+
+		return transaction
+	}
+
 	err = i.tradingService.PlaceOrder(ctx, pair)
 	log.Println(transaction)
 	if err != nil {
@@ -81,7 +172,90 @@ func (i investingService) investInPair(pair domain.DCAPair) *domain.Transaction
 			err = fmt.Errorf("failed to notify %s transaction failure : %w", pair.Pair, notifyErr)
 		}
 		err = fmt.Errorf("could not place order on %s : %w", pair.Pair, err)
+
+		return transaction
+	}
+
+	if transaction.Id != domain.StagedTransactionId {
+		recordErr := i.history.Record(transaction)
+		if recordErr != nil {
+			log.Printf("The %s transaction could not be recorded in the history : %v", pair.Pair, recordErr)
+		}
+
+		i.recordDeployment(round.deploymentShares[pair.Pair])
+		i.saveRollover(pair.Pair, rollover)
 	}
 
 	return transaction
 }
+
+// amount Get the amount to invest in the pair during the current round, and the price rules rollover to save once it's
+// invested. The base amount is computed by the pair strategy then adjusted by its multipliers, which are recorded on
+// the transaction. The adjusted amount of a budget pair is bounded by what is left of its monthly share.
+func (i investingService) amount(pair domain.DCAPair, transaction *domain.Transaction, round round) (float64, *float64, error) {
+	if amount, ok := round.amounts[pair.Pair]; ok {
+		return amount, nil, nil
+	}
+
+	amount := pair.Amount
+	remaining := -1.0
+	var err error
+	switch {
+	case pair.ValueAveraging != nil:
+		amount, err = i.valueAveragingAmount(pair)
+	case pair.Goal != nil:
+		amount, err = i.goalAmount(pair, transaction)
+	case i.config.Budget != nil && i.config.Budget.Allocates(pair.Pair):
+		amount, remaining, err = i.budgetAmount(pair)
+	case pair.BalancePercentage != nil:
+		amount, err = i.balancePercentageAmount(pair, round)
+	}
+	if err != nil {
+		return -1, nil, err
+	}
+
+	if pair.Drawdown != nil {
+		multiplier, reason, err := i.drawdownMultiplier(pair)
+		if err != nil {
+			return -1, nil, err
+		}
+
+		transaction.Adjust(multiplier, reason)
+		amount = amount * multiplier
+	}
+
+	if pair.Indicators != nil {
+		multiplier, reason, err := i.indicatorsMultiplier(pair)
+		if err != nil {
+			return -1, nil, err
+		}
+
+		transaction.Adjust(multiplier, reason)
+		amount = pair.Indicators.Bound(amount * multiplier)
+		log.Printf("[%s] Indicators adjusted amount : %.2f€", pair.Pair, amount)
+	}
+
+	var rollover *float64
+	if pair.PriceRules != nil {
+		amount, rollover, err = i.applyPriceRules(pair, amount, transaction)
+		if err != nil {
+			return amount, rollover, err
+		}
+	}
+
+	if remaining >= 0 {
+		amount = math.Min(amount, remaining)
+		transaction.Budgeted = amount
+	}
+
+	return amount, rollover, nil
+}
+
+// skipError is returned while computing a pair amount when the pair must not be invested in during the round
+type skipError struct {
+	reason string
+}
+
+func (s skipError) Error() string {
+	return s.reason
+}
@@ -1,11 +1,13 @@
 package kraken
 
 import (
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"kraken-dca-bot/internal/domain"
 	"kraken-dca-bot/internal/mocks"
 	"testing"
+	"time"
 )
 
 var config = domain.Config{
@@ -22,6 +24,14 @@ var config = domain.Config{
 	},
 }
 
+// newHistory Get a history mock accepting any recorded transaction
+func newHistory(controller *gomock.Controller) *mocks.MockHistory {
+	history := mocks.NewMockHistory(controller)
+	history.EXPECT().Record(gomock.Any()).Return(nil).AnyTimes()
+
+	return history
+}
+
 func TestInvestSuccess(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -30,7 +40,7 @@ func TestInvestSuccess(t *testing.T) {
 	tradingService := mocks.NewMockTrader(controller)
 	notifier := mocks.NewMockNotifier(controller)
 
-	investingService := NewInvestingService(config, accountService, tradingService, notifier)
+	investingService := NewInvestingService(config, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
 
 	accountService.EXPECT().Balance("ZEUR").Return(35.23, nil)
 	accountService.EXPECT().Balance("ZEUR").Return(15.23, nil)
@@ -61,7 +71,7 @@ func TestInvestFail_BalanceFail(t *testing.T) {
 	tradingService := mocks.NewMockTrader(controller)
 	notifier := mocks.NewMockNotifier(controller)
 
-	investingService := NewInvestingService(config, accountService, tradingService, notifier)
+	investingService := NewInvestingService(config, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
 
 	accountService.EXPECT().Balance("ZEUR").Return(0.0, errors.New("balance error")).Times(2)
 
@@ -92,7 +102,7 @@ func TestInvestFail_BalanceInsufficient(t *testing.T) {
 	tradingService := mocks.NewMockTrader(controller)
 	notifier := mocks.NewMockNotifier(controller)
 
-	investingService := NewInvestingService(config, accountService, tradingService, notifier)
+	investingService := NewInvestingService(config, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
 
 	accountService.EXPECT().Balance("ZEUR").Return(23.08, nil)
 	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Return(nil)
@@ -125,7 +135,7 @@ func TestInvestFail_PlaceOrderFail(t *testing.T) {
 	tradingService := mocks.NewMockTrader(controller)
 	notifier := mocks.NewMockNotifier(controller)
 
-	investingService := NewInvestingService(config, accountService, tradingService, notifier)
+	investingService := NewInvestingService(config, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
 
 	accountService.EXPECT().Balance("ZEUR").Return(45.44, nil)
 	tradingService.EXPECT().PlaceOrder(gomock.Any(), config.Pairs[0]).Return(nil)
@@ -164,7 +174,7 @@ func TestInvestFail_PlaceOrderFailNotifierFail(t *testing.T) {
 	tradingService := mocks.NewMockTrader(controller)
 	notifier := mocks.NewMockNotifier(controller)
 
-	investingService := NewInvestingService(config, accountService, tradingService, notifier)
+	investingService := NewInvestingService(config, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
 
 	accountService.EXPECT().Balance("ZEUR").Return(45.44, nil)
 	tradingService.EXPECT().PlaceOrder(gomock.Any(), config.Pairs[0]).Return(nil)
@@ -190,3 +200,601 @@ func TestInvestFail_PlaceOrderFailNotifierFail(t *testing.T) {
 		t.Errorf("Second transaction pair is %v", transactions[1].Exception)
 	}
 }
+
+func TestInvestValueAveraging(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	valueAveragingConfig := domain.Config{
+		Frequency: "1w",
+		Currency:  "ZEUR",
+		Pairs: []domain.DCAPair{
+			{
+				Pair: "XXBTZEUR",
+				ValueAveraging: &domain.ValueAveraging{
+					Start:     time.Now().Add(-15 * 24 * time.Hour),
+					Increment: 100,
+					Max:       150,
+				},
+			},
+			{
+				Pair: "XETHZEUR",
+				ValueAveraging: &domain.ValueAveraging{
+					Start:     time.Now().Add(-24 * time.Hour),
+					Increment: 100,
+				},
+			},
+		},
+	}
+	investingService := NewInvestingService(valueAveragingConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	tradingService.EXPECT().BaseAsset("XXBTZEUR").Return("XXBT", nil)
+	accountService.EXPECT().Holdings("XXBT").Return(0.01, nil)
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(20000.0, nil)
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, pair domain.DCAPair) {
+		if pair.Amount != 100 {
+			t.Errorf("The XXBTZEUR amount is %f instead of 100", pair.Amount)
+		}
+	}).Return(nil)
+	tradingService.EXPECT().BaseAsset("XETHZEUR").Return("XETH", nil)
+	accountService.EXPECT().Holdings("XETH").Return(0.1, nil)
+	tradingService.EXPECT().AskPrice("XETHZEUR").Return(1500.0, nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Exception != nil || transactions[0].SkipReason != "" {
+		t.Errorf("First transaction wasn't invested : %v", transactions[0])
+	}
+
+	if transactions[1].Exception != nil || transactions[1].SkipReason != "the holdings value 150.00 is ahead of the 100.00 target" {
+		t.Errorf("Second transaction wasn't skipped : %v", transactions[1])
+	}
+}
+
+func TestInvestDrawdown(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	drawdownConfig := domain.Config{
+		Currency: "ZEUR",
+		Pairs: []domain.DCAPair{
+			{
+				Pair:   "XXBTZEUR",
+				Amount: 10,
+				Drawdown: &domain.Drawdown{
+					Days: 2,
+					Tiers: []domain.DrawdownTier{
+						{Drawdown: 20, Multiplier: 1.5},
+						{Drawdown: 40, Multiplier: 2},
+					},
+				},
+			},
+		},
+	}
+	investingService := NewInvestingService(drawdownConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	tradingService.EXPECT().Candles("XXBTZEUR", 1440).Return([]domain.Candle{{High: 50000}, {High: 30000}, {High: 25000}}, nil)
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(21000.0, nil)
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, pair domain.DCAPair) {
+		if pair.Amount != 15 {
+			t.Errorf("The XXBTZEUR amount is %f instead of 15", pair.Amount)
+		}
+	}).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Multiplier != 1.5 || transactions[0].MultiplierReason != "30.0% drawdown from the 2-day high of 30000.00 (-20% tier)" {
+		t.Errorf("The transaction multiplier is %f (%s)", transactions[0].Multiplier, transactions[0].MultiplierReason)
+	}
+}
+
+func TestInvestIndicators(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	indicatorsConfig := domain.Config{
+		Currency: "ZEUR",
+		Pairs: []domain.DCAPair{
+			{
+				Pair:   "XXBTZEUR",
+				Amount: 10,
+				Indicators: &domain.Indicators{
+					Min: 5,
+					Max: 18,
+					MovingAverage: &domain.IndicatorRule{
+						Period: 3,
+						Ranges: []domain.IndicatorRange{{From: -100, To: -10, Multiplier: 2}},
+					},
+					RSI: &domain.IndicatorRule{
+						Period: 2,
+						Ranges: []domain.IndicatorRange{{From: 0, To: 30, Multiplier: 1.5}},
+					},
+				},
+			},
+		},
+	}
+	investingService := NewInvestingService(indicatorsConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	candles := []domain.Candle{{Close: 100}, {Close: 100}, {Close: 90}, {Close: 80}}
+	tradingService.EXPECT().Candles("XXBTZEUR", 1440).Return(candles, nil)
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(72.0, nil)
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, pair domain.DCAPair) {
+		if pair.Amount != 18 {
+			t.Errorf("The XXBTZEUR amount is %f instead of 18", pair.Amount)
+		}
+	}).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Multiplier != 3 || transactions[0].MultiplierReason != "3-day MA distance -20.0% (2.00x), 2-day RSI 0.0 (1.50x)" {
+		t.Errorf("The transaction multiplier is %f (%s)", transactions[0].Multiplier, transactions[0].MultiplierReason)
+	}
+}
+
+func TestInvestFail_CapExceeded(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	history := mocks.NewMockHistory(controller)
+
+	capsConfig := domain.Config{
+		Currency: "ZEUR",
+		Caps:     domain.Caps{Monthly: 100},
+		Pairs: []domain.DCAPair{
+			{Pair: "XETHZEUR", Amount: 20.00},
+			{Pair: "XXBTZEUR", Amount: 10.00, Caps: &domain.Caps{Daily: 15}},
+		},
+	}
+	investingService := NewInvestingService(capsConfig, accountService, tradingService, notifier, history, mocks.NewMockState(controller))
+
+	history.EXPECT().Transactions().Return([]domain.Transaction{
+		{Id: "TX1", Pair: "XETHZEUR", Date: time.Now(), MarketPrice: 1000, Amount: 0.05},
+		{Id: "TX2", Pair: "XXBTZEUR", Date: time.Now(), MarketPrice: 20000, Amount: 0.0005},
+	}, nil).Times(2)
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil).Times(2)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), capsConfig.Pairs[0]).Return(nil)
+	history.EXPECT().Record(gomock.Any()).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Exception != nil {
+		t.Errorf("First transaction exception is %v", transactions[0].Exception)
+	}
+
+	if transactions[1].Exception == nil || transactions[1].Exception.Error() != "the XXBTZEUR purchase was blocked : XXBTZEUR spending cap : the daily cap of 15.00 would be exceeded : 10.00 already spent, 10.00 requested" {
+		t.Errorf("Second transaction exception is %v", transactions[1].Exception)
+	}
+}
+
+func TestInvestPriceRulesRollover(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	state := mocks.NewMockState(controller)
+
+	priceRulesConfig := domain.Config{
+		Currency: "ZEUR",
+		Pairs: []domain.DCAPair{
+			{
+				Pair:       "XXBTZEUR",
+				Amount:     10,
+				PriceRules: &domain.PriceRules{Ceiling: 80000, Rollover: true},
+			},
+		},
+	}
+	investingService := NewInvestingService(priceRulesConfig, accountService, tradingService, notifier, newHistory(controller), state)
+
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(85000.0, nil)
+	state.EXPECT().Load("rollover/XXBTZEUR", gomock.Any()).Return(false, nil)
+	tradingService.EXPECT().Staging().Return(false)
+	state.EXPECT().Save("rollover/XXBTZEUR", 10.0).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].SkipReason != "price 85000.00 above the 80000.00 ceiling" {
+		t.Errorf("The first round wasn't skipped : %v", transactions[0])
+	}
+
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(75000.0, nil)
+	state.EXPECT().Load("rollover/XXBTZEUR", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*float64) = 10
+
+		return true, nil
+	})
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Return(errors.New("EService:Unavailable"))
+	notifier.EXPECT().NotifyFailure(gomock.Any()).Return(nil)
+
+	// The rollover is kept when the order fails
+	transactions = investingService.Invest()
+
+	if transactions[0].Exception == nil {
+		t.Errorf("The second round should fail : %v", transactions[0])
+	}
+
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(75000.0, nil)
+	state.EXPECT().Load("rollover/XXBTZEUR", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*float64) = 10
+
+		return true, nil
+	})
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, pair domain.DCAPair) {
+		if pair.Amount != 20 {
+			t.Errorf("The XXBTZEUR amount is %f instead of 20", pair.Amount)
+		}
+	}).Return(nil)
+	tradingService.EXPECT().Staging().Return(false)
+	state.EXPECT().Save("rollover/XXBTZEUR", 0.0).Return(nil)
+
+	transactions = investingService.Invest()
+
+	if transactions[0].Exception != nil || transactions[0].SkipReason != "" {
+		t.Errorf("The third round wasn't invested : %v", transactions[0])
+	}
+}
+
+func TestInvestDeploymentPlan(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	state := mocks.NewMockState(controller)
+
+	deploymentConfig := domain.Config{
+		Frequency: "1w",
+		Currency:  "ZEUR",
+		Pairs:     config.Pairs,
+		Deployments: []domain.DeploymentPlan{
+			{
+				Name:       "bonus",
+				Total:      6000,
+				Start:      time.Now().Add(-24 * time.Hour),
+				End:        time.Now().Add(3*7*24*time.Hour + time.Hour),
+				Allocation: map[string]float64{"XETHZEUR": 60, "XXBTZEUR": 40},
+			},
+		},
+	}
+	investingService := NewInvestingService(deploymentConfig, accountService, tradingService, notifier, newHistory(controller), state)
+
+	state.EXPECT().Load("deployment/bonus", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*float64) = 2000
+
+		return true, nil
+	})
+	accountService.EXPECT().Balance("ZEUR").Return(5000.0, nil).Times(2)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), domain.DCAPair{Pair: "XETHZEUR", Amount: 620}).Return(nil)
+	state.EXPECT().Load("deployment/bonus", gomock.Any()).Return(true, nil)
+	state.EXPECT().Save("deployment/bonus", 600.0).Return(nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), domain.DCAPair{Pair: "XXBTZEUR", Amount: 410}).Return(errors.New("place order error"))
+	notifier.EXPECT().NotifyFailure(gomock.Any()).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Exception != nil || transactions[1].Exception == nil {
+		t.Errorf("The transactions are %v", transactions)
+	}
+}
+
+func TestInvestGoal(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	goalConfig := domain.Config{
+		Frequency: "1w",
+		Currency:  "ZEUR",
+		Pairs: []domain.DCAPair{
+			{
+				Pair: "XXBTZEUR",
+				Goal: &domain.Goal{
+					Quantity: 0.5,
+					Deadline: time.Now().Add(3*7*24*time.Hour + time.Hour),
+					Max:      1000,
+				},
+			},
+		},
+	}
+	investingService := NewInvestingService(goalConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	tradingService.EXPECT().BaseAsset("XXBTZEUR").Return("XXBT", nil)
+	accountService.EXPECT().Holdings("XXBT").Return(0.1, nil)
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(20000.0, nil)
+	accountService.EXPECT().Balance("ZEUR").Return(5000.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, pair domain.DCAPair) {
+		if pair.Amount != 1000 {
+			t.Errorf("The XXBTZEUR amount is %f instead of 1000", pair.Amount)
+		}
+	}).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Goal == nil || transactions[0].Goal.Percentage() != 20 {
+		t.Errorf("The goal progress is %v", transactions[0].Goal)
+	}
+}
+
+func TestInvestMonthlyBudget(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	history := mocks.NewMockHistory(controller)
+
+	budgetConfig := domain.Config{
+		Frequency: "1ms",
+		Currency:  "ZEUR",
+		Pairs:     []domain.DCAPair{{Pair: "XXBTZEUR"}},
+		Budget: &domain.Budget{
+			Monthly:    300,
+			Allocation: map[string]float64{"XXBTZEUR": 100},
+		},
+	}
+	investingService := NewInvestingService(budgetConfig, accountService, tradingService, notifier, history, mocks.NewMockState(controller))
+
+	history.EXPECT().Transactions().Return([]domain.Transaction{
+		{Id: "TX1", Pair: "XXBTZEUR", Date: time.Now(), MarketPrice: 20000, Amount: 0.015, Budgeted: 300},
+	}, nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].SkipReason != "the monthly budget is already spent" {
+		t.Errorf("The transaction wasn't skipped : %v", transactions[0])
+	}
+}
+
+func TestInvestMonthlyBudgetBounded(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	history := newHistory(controller)
+
+	budgetConfig := domain.Config{
+		Frequency: "1w",
+		Currency:  "ZEUR",
+		Pairs: []domain.DCAPair{{
+			Pair:       "XXBTZEUR",
+			PriceRules: &domain.PriceRules{Floor: 30000, FloorMultiplier: 2},
+		}},
+		Budget: &domain.Budget{
+			Monthly:    300,
+			Allocation: map[string]float64{"XXBTZEUR": 100},
+		},
+	}
+	now := time.Date(2022, 10, 31, 12, 0, 0, 0, time.UTC)
+	investingService := NewInvestingServiceWithClock(budgetConfig, accountService, tradingService, notifier, history, mocks.NewMockState(controller), func() time.Time { return now })
+
+	// The deployment plan share bought on top of the budget is left out of the budget spending
+	history.EXPECT().Transactions().Return([]domain.Transaction{
+		{Id: "TX1", Pair: "XXBTZEUR", Side: domain.Buy, Date: now, MarketPrice: 20000, Amount: 0.0125, Budgeted: 250},
+		{Id: "TX2", Pair: "XXBTZEUR", Side: domain.Buy, Date: now, MarketPrice: 20000, Amount: 0.05},
+	}, nil)
+	tradingService.EXPECT().AskPrice("XXBTZEUR").Return(20000.0, nil)
+	accountService.EXPECT().Balance("ZEUR").Return(500.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, pair domain.DCAPair) {
+		if pair.Amount != 50 {
+			t.Errorf("The XXBTZEUR amount is %f instead of the 50 left in the budget", pair.Amount)
+		}
+	}).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Budgeted != 50 {
+		t.Errorf("The budgeted amount is %f instead of 50", transactions[0].Budgeted)
+	}
+}
+
+func TestInvestBalancePercentage(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	percentageConfig := domain.Config{
+		Currency: "ZEUR",
+		Pairs: []domain.DCAPair{
+			{Pair: "XXBTZEUR", BalancePercentage: &domain.BalancePercentage{Percentage: 50}},
+			{Pair: "XETHZEUR", BalancePercentage: &domain.BalancePercentage{Percentage: 20}},
+		},
+	}
+	investingService := NewInvestingService(percentageConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	accountService.EXPECT().Balance("ZEUR").Return(200.0, nil)
+	accountService.EXPECT().Balance("ZEUR").Return(200.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), domain.DCAPair{Pair: "XXBTZEUR", Amount: 100, BalancePercentage: percentageConfig.Pairs[0].BalancePercentage}).Return(nil)
+	accountService.EXPECT().Balance("ZEUR").Return(100.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), domain.DCAPair{Pair: "XETHZEUR", Amount: 40, BalancePercentage: percentageConfig.Pairs[1].BalancePercentage}).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if transactions[0].Exception != nil || transactions[1].Exception != nil {
+		t.Errorf("The transactions are %v", transactions)
+	}
+}
+
+func TestInvestSell(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	sellConfig := domain.Config{
+		Currency: "ZEUR",
+		Sells: []domain.SellPair{
+			{Pair: "XXBTZEUR", Amount: 100},
+			{Pair: "XETHZEUR", Quantity: 0.5, Floor: 2000},
+			{Pair: "XLTCZEUR", Quantity: 2},
+		},
+	}
+	investingService := NewInvestingService(sellConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	tradingService.EXPECT().BidPrice("XXBTZEUR").Return(20000.0, nil)
+	tradingService.EXPECT().BaseAsset("XXBTZEUR").Return("XXBT", nil)
+	accountService.EXPECT().Holdings("XXBT").Return(1.0, nil)
+	tradingService.EXPECT().PlaceSellOrder(gomock.Any(), "XXBTZEUR", 0.005).DoAndReturn(func(ctx context.Context, pair string, volume float64) error {
+		ctx.Value("transaction").(*domain.Transaction).Complete("TXID", 20000, volume, 0)
+
+		return nil
+	})
+	tradingService.EXPECT().BidPrice("XETHZEUR").Return(1500.0, nil)
+	tradingService.EXPECT().BidPrice("XLTCZEUR").Return(80.0, nil)
+	tradingService.EXPECT().BaseAsset("XLTCZEUR").Return("XLTC", nil)
+	accountService.EXPECT().Holdings("XLTC").Return(1.5, nil)
+
+	transactions := investingService.Invest()
+
+	if len(transactions) != 3 {
+		t.Fatalf("Transaction count is wrong : %v", len(transactions))
+	}
+
+	if !transactions[0].IsSell() || transactions[0].Id != "TXID" || transactions[0].Exception != nil {
+		t.Errorf("The XXBTZEUR sale is %v", transactions[0])
+	}
+
+	if transactions[1].SkipReason != "the 1500.00€ price is below the 2000.00€ sell floor" {
+		t.Errorf("The XETHZEUR sale should be skipped : %v", transactions[1])
+	}
+
+	if transactions[2].Exception == nil || transactions[2].Exception.Error() != "the 1.500000 XLTC held are less than the 2.000000 to sell" {
+		t.Errorf("The XLTCZEUR sale should fail : %v", transactions[2].Exception)
+	}
+}
+
+func TestInvestTakeProfit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	history := mocks.NewMockHistory(controller)
+	state := mocks.NewMockState(controller)
+
+	takeProfitConfig := domain.Config{
+		Currency: "ZEUR",
+		Pairs: []domain.DCAPair{
+			{Pair: "XXBTZEUR", Amount: 20, TakeProfit: &domain.TakeProfit{Rungs: []domain.Rung{
+				{Gain: 100, Percentage: 5},
+				{Gain: 200, Percentage: 10},
+				{Gain: 300, Percentage: 20},
+			}}},
+		},
+	}
+	investingService := NewInvestingService(takeProfitConfig, accountService, tradingService, notifier, history, state)
+
+	accountService.EXPECT().Balance("ZEUR").Return(100.0, nil)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), gomock.Any()).Return(nil)
+	history.EXPECT().Transactions().Return([]domain.Transaction{
+		{Id: "TX1", Pair: "XXBTZEUR", MarketPrice: 10000, Amount: 1},
+	}, nil)
+	tradingService.EXPECT().BidPrice("XXBTZEUR").Return(35000.0, nil)
+	state.EXPECT().Load("takeprofit/XXBTZEUR", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*[]float64) = []float64{100}
+
+		return true, nil
+	})
+	tradingService.EXPECT().BaseAsset("XXBTZEUR").Return("XXBT", nil)
+	accountService.EXPECT().Holdings("XXBT").Return(2.0, nil)
+	tradingService.EXPECT().PlaceSellOrder(gomock.Any(), "XXBTZEUR", 0.2).DoAndReturn(func(ctx context.Context, pair string, volume float64) error {
+		ctx.Value("transaction").(*domain.Transaction).Complete("TXID", 35000, volume, 0)
+
+		return nil
+	})
+	history.EXPECT().Record(gomock.Any()).Return(nil).Times(2)
+	state.EXPECT().Save("takeprofit/XXBTZEUR", []float64{100, 200}).Return(nil)
+	notifier.EXPECT().NotifyTakeProfit(gomock.Any()).Return(nil)
+
+	transactions := investingService.Invest()
+
+	if len(transactions) != 2 {
+		t.Fatalf("Transaction count is wrong : %v", len(transactions))
+	}
+
+	sale := transactions[1]
+	if !sale.IsSell() || sale.Rung == nil || sale.Rung.Gain != 200 || sale.Exception != nil {
+		t.Errorf("The take-profit sale is %v", sale)
+	}
+}
+
+func TestInvestStaking(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	tradingService := mocks.NewMockTrader(controller)
+	notifier := mocks.NewMockNotifier(controller)
+
+	stakingConfig := domain.Config{
+		Currency: "ZEUR",
+		Pairs: []domain.DCAPair{
+			{Pair: "XETHZEUR", Amount: 20, Staking: &domain.Staking{}},
+			{Pair: "DOTEUR", Amount: 10, Staking: &domain.Staking{Asset: "DOT", Strategy: "DOT-BONDED"}},
+		},
+	}
+	investingService := NewInvestingService(stakingConfig, accountService, tradingService, notifier, newHistory(controller), mocks.NewMockState(controller))
+
+	complete := func(ctx context.Context, pair domain.DCAPair) error {
+		ctx.Value("transaction").(*domain.Transaction).Complete("TXID", 100, 0.1, 0.001)
+
+		return nil
+	}
+	accountService.EXPECT().Balance("ZEUR").Return(100.0, nil).Times(2)
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), stakingConfig.Pairs[0]).DoAndReturn(complete)
+	tradingService.EXPECT().BaseAsset("XETHZEUR").Return("XETH", nil)
+	accountService.EXPECT().Stake("XETH", 0.1, "").Return("", errors.New("stake error"))
+	tradingService.EXPECT().PlaceOrder(gomock.Any(), stakingConfig.Pairs[1]).DoAndReturn(complete)
+	accountService.EXPECT().Stake("DOT", 0.1, "DOT-BONDED").Return("DOT-BONDED", nil)
+
+	transactions := investingService.Invest()
+
+	if len(transactions) != 4 {
+		t.Fatalf("Transaction count is wrong : %v", len(transactions))
+	}
+
+	if transactions[0].Exception != nil || transactions[2].Exception != nil {
+		t.Errorf("The purchases should succeed : %v", transactions)
+	}
+
+	if !transactions[1].IsStake() || transactions[1].Exception == nil || transactions[1].Exception.Error() != "the XETHZEUR purchase could not be staked : stake error" {
+		t.Errorf("The XETHZEUR stake should fail : %v", transactions[1].Exception)
+	}
+
+	if !transactions[3].IsStake() || transactions[3].Id != "DOT-BONDED" || transactions[3].Amount != 0.1 {
+		t.Errorf("The DOTEUR stake is %v", transactions[3])
+	}
+}
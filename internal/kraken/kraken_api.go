@@ -2,10 +2,152 @@ package kraken
 
 //go:generate mockgen -destination=../mocks/mock_kraken_api.go -package=mocks . ApiInterface
 
-import krakenapi "github.com/beldur/kraken-go-api-client"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	krakenapi "github.com/beldur/kraken-go-api-client"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
 
 type ApiInterface interface {
-	Balance() (*krakenapi.BalanceResponse, error)
 	Query(method string, data map[string]string) (interface{}, error)
 	AddOrder(pair string, direction string, orderType string, volume string, args map[string]string) (*krakenapi.AddOrderResponse, error)
 }
+
+// extendedMethods are the private methods unknown to the Kraken client library, queried by Api itself
+var extendedMethods = []string{
+	"Earn/Strategies",
+	"Earn/Allocate",
+}
+
+// extendedPublicMethods are the public methods unknown to the Kraken client library, queried by Api itself
+var extendedPublicMethods = []string{
+	"SystemStatus",
+}
+
+// Api is the Kraken client library extended with the methods it doesn't support
+type Api struct {
+	*krakenapi.KrakenAPI
+	key    string
+	secret string
+	client *http.Client
+}
+
+func NewApi(key string, secret string) ApiInterface {
+	return NewApiWithClient(key, secret, http.DefaultClient)
+}
+
+// NewApiWithClient Get an Api sending its requests with the given HTTP client
+func NewApiWithClient(key string, secret string, client *http.Client) ApiInterface {
+	return Api{
+		KrakenAPI: krakenapi.NewWithClient(key, secret, client),
+		key:       key,
+		secret:    secret,
+		client:    client,
+	}
+}
+
+// Query Send a query to the Kraken API for the given method and parameters
+func (a Api) Query(method string, data map[string]string) (interface{}, error) {
+	for _, extended := range extendedMethods {
+		if method == extended {
+			return a.queryPrivate(method, data)
+		}
+	}
+
+	for _, extended := range extendedPublicMethods {
+		if method == extended {
+			return a.queryPublic(method, data)
+		}
+	}
+
+	return a.KrakenAPI.Query(method, data)
+}
+
+// queryPublic Send an unsigned query to a public method
+func (a Api) queryPublic(method string, data map[string]string) (interface{}, error) {
+	values := url.Values{}
+	for key, value := range data {
+		values.Set(key, value)
+	}
+
+	path := fmt.Sprintf("/%s/public/%s", krakenapi.APIVersion, method)
+	request, err := http.NewRequest(http.MethodPost, krakenapi.APIURL+path, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("User-Agent", krakenapi.APIUserAgent)
+
+	return a.send(method, request)
+}
+
+// queryPrivate Send a signed query to a private method, as described in https://docs.kraken.com/rest/#section/Authentication
+func (a Api) queryPrivate(method string, data map[string]string) (interface{}, error) {
+	values := url.Values{}
+	for key, value := range data {
+		values.Set(key, value)
+	}
+	values.Set("nonce", fmt.Sprintf("%d", time.Now().UnixNano()))
+
+	path := fmt.Sprintf("/%s/private/%s", krakenapi.APIVersion, method)
+	secret, err := base64.StdEncoding.DecodeString(a.secret)
+	if err != nil {
+		return nil, fmt.Errorf("the API secret cannot be decoded : %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, krakenapi.APIURL+path, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("User-Agent", krakenapi.APIUserAgent)
+	request.Header.Set("API-Key", a.key)
+	request.Header.Set("API-Sign", signature(path, values, secret))
+
+	return a.send(method, request)
+}
+
+// send Send the request of the method and get the result of the Kraken response
+func (a Api) send(method string, request *http.Request) (interface{}, error) {
+	response, err := a.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("the %s request failed : %w", method, err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("the %s response cannot be read : %w", method, err)
+	}
+
+	var krakenResponse krakenapi.KrakenResponse
+	err = json.Unmarshal(body, &krakenResponse)
+	if err != nil {
+		return nil, fmt.Errorf("the %s response cannot be parsed : %w", method, err)
+	}
+
+	if len(krakenResponse.Error) > 0 {
+		return nil, fmt.Errorf("the %s request was refused : %s", method, strings.Join(krakenResponse.Error, ", "))
+	}
+
+	return krakenResponse.Result, nil
+}
+
+// signature Get the API-Sign header of a private request : the HMAC-SHA512 of the path and the SHA256 of the nonce
+// and the encoded values, keyed with the decoded API secret
+func signature(path string, values url.Values, secret []byte) string {
+	digest := sha256.Sum256([]byte(values.Get("nonce") + values.Encode()))
+	mac := hmac.New(sha512.New, secret)
+	mac.Write(append([]byte(path), digest[:]...))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
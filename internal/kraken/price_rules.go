@@ -0,0 +1,73 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+func rolloverKey(pair string) string {
+	return "rollover/" + pair
+}
+
+// applyPriceRules Get the amount to invest in the pair once its price ceiling and floor rules are applied to its ask
+// price, and the amount to roll into the next rounds when the pair rules require it. The rollover is nil when it
+// doesn't change, and is only saved by saveRollover once the round is invested or skipped.
+func (i investingService) applyPriceRules(pair domain.DCAPair, amount float64, transaction *domain.Transaction) (float64, *float64, error) {
+	askPrice, err := i.tradingService.AskPrice(pair.Pair)
+	if err != nil {
+		return -1, nil, fmt.Errorf("cannot get the %s ask price : %w", pair.Pair, err)
+	}
+
+	rules := pair.PriceRules
+	rollover := 0.0
+	if rules.Rollover {
+		_, err = i.state.Load(rolloverKey(pair.Pair), &rollover)
+		if err != nil {
+			return -1, nil, err
+		}
+	}
+
+	multiplier, reason := rules.Multiplier(askPrice)
+	adjusted := amount * multiplier
+
+	if rules.AboveCeiling(askPrice) {
+		var next *float64
+		if rules.Rollover {
+			next = new(float64)
+			*next = rollover + amount - adjusted
+		}
+
+		if adjusted <= 0 {
+			return 0, next, skipError{reason: reason}
+		}
+
+		transaction.Adjust(multiplier, reason)
+
+		return adjusted, next, nil
+	}
+
+	if reason != "" {
+		transaction.Adjust(multiplier, reason)
+	}
+
+	if rollover > 0 {
+		log.Printf("[%s] Rolling over %.2f€ from the previous rounds", pair.Pair, rollover)
+
+		return adjusted + rollover, new(float64), nil
+	}
+
+	return adjusted, nil, nil
+}
+
+// saveRollover Save the amount rolled into the next rounds of the pair, if it changed. Nothing is saved in staging.
+func (i investingService) saveRollover(pair string, rollover *float64) {
+	if rollover == nil || i.tradingService.Staging() {
+		return
+	}
+
+	err := i.state.Save(rolloverKey(pair), *rollover)
+	if err != nil {
+		log.Printf("The %s rollover could not be saved : %v", pair, err)
+	}
+}
@@ -0,0 +1,39 @@
+package kraken
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// round holds the values computed once at the beginning of an investment round and shared by all its pairs
+type round struct {
+	start time.Time
+	// balance is the quote currency balance snapshot used by the pairs investing a percentage of it
+	balance          float64
+	balanceErr       error
+	deploymentShares map[string][]deploymentShare
+	// amounts are the fixed amounts invested in each pair when the round is not a scheduled one
+	amounts map[string]float64
+}
+
+func (i investingService) newRound(start time.Time) round {
+	r := round{
+		start:            start,
+		deploymentShares: i.deploymentShares(start),
+	}
+
+	for _, pair := range i.config.Pairs {
+		if pair.BalancePercentage != nil {
+			r.balance, r.balanceErr = i.accountService.Balance(i.config.Currency)
+			if r.balanceErr != nil {
+				r.balanceErr = fmt.Errorf("account balance cannot be collected : %w", r.balanceErr)
+			}
+			log.Printf("Round balance snapshot : %.2f€", r.balance)
+
+			break
+		}
+	}
+
+	return r
+}
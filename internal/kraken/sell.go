@@ -0,0 +1,89 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// sellPair Sell the round quantity of the pair base asset, unless its price is below the sell floor.
+// The sale fails when the account holds less than the quantity to sell.
+func (i investingService) sellPair(sell domain.SellPair) *domain.Transaction {
+	transaction := domain.NewSellTransaction(sell.Pair)
+	transaction.Date = i.now()
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, "transaction", transaction)
+
+	var err error
+	defer func() {
+		if err != nil {
+			transaction.Fail(err)
+		}
+	}()
+
+	price, err := i.tradingService.BidPrice(sell.Pair)
+	if err != nil {
+		err = fmt.Errorf("the %s bid price cannot be collected : %w", sell.Pair, err)
+
+		return transaction
+	}
+
+	if sell.BelowFloor(price) {
+		transaction.Skip(fmt.Sprintf("the %.2f€ price is below the %.2f€ sell floor", price, sell.Floor))
+		log.Println(transaction)
+
+		return transaction
+	}
+
+	asset, err := i.tradingService.BaseAsset(sell.Pair)
+	if err != nil {
+		err = fmt.Errorf("the %s base asset cannot be collected : %w", sell.Pair, err)
+
+		return transaction
+	}
+
+	holdings, err := i.accountService.Holdings(asset)
+	if err != nil {
+		err = fmt.Errorf("the %s holdings cannot be collected : %w", asset, err)
+
+		return transaction
+	}
+
+	volume := sell.Volume(price)
+	log.Printf("[%s] Selling %f of the %f %s held", sell.Pair, volume, holdings, asset)
+	if holdings < volume {
+		err = fmt.Errorf("the %f %s held are less than the %f to sell", holdings, asset, volume)
+
+		return transaction
+	}
+
+	err = i.placeSellOrder(ctx, sell.Pair, volume)
+
+	return transaction
+}
+
+// placeSellOrder Sell `volume` of the pair base asset, notifying failures and recording successful sales in the history
+func (i investingService) placeSellOrder(ctx context.Context, pair string, volume float64) error {
+	transaction := ctx.Value("transaction").(*domain.Transaction)
+
+	err := i.tradingService.PlaceSellOrder(ctx, pair, volume)
+	log.Println(transaction)
+	if err != nil {
+		notifyErr := i.notifier.NotifyFailure(transaction)
+		if notifyErr != nil {
+			err = fmt.Errorf("failed to notify %s transaction failure : %w", pair, notifyErr)
+		}
+
+		return fmt.Errorf("could not place sell order on %s : %w", pair, err)
+	}
+
+	if transaction.Id != domain.StagedTransactionId {
+		recordErr := i.history.Record(transaction)
+		if recordErr != nil {
+			log.Printf("The %s transaction could not be recorded in the history : %v", pair, recordErr)
+		}
+	}
+
+	return nil
+}
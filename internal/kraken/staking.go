@@ -0,0 +1,49 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// stakePurchase Allocate the volume bought by a successful purchase to the pair Earn strategy.
+// The stake is a follow-up transaction, its failure leaving the purchase untouched. No stake is made for staged or
+// failed purchases, nor for pairs without staking.
+func (i investingService) stakePurchase(pair domain.DCAPair, purchase *domain.Transaction) *domain.Transaction {
+	if pair.Staking == nil || purchase.Exception != nil || purchase.Id == "" || purchase.Id == domain.StagedTransactionId {
+		return nil
+	}
+
+	transaction := domain.NewStakeTransaction(pair.Pair)
+	transaction.Date = i.now()
+
+	asset := pair.Staking.Asset
+	if asset == "" {
+		var err error
+		asset, err = i.tradingService.BaseAsset(pair.Pair)
+		if err != nil {
+			transaction.Fail(fmt.Errorf("the %s purchase could not be staked, its base asset is unknown : %w", pair.Pair, err))
+			log.Println(transaction.Exception)
+
+			return transaction
+		}
+	}
+
+	strategy, err := i.accountService.Stake(asset, purchase.Amount, pair.Staking.Strategy)
+	if err != nil {
+		transaction.Fail(fmt.Errorf("the %s purchase could not be staked : %w", pair.Pair, err))
+		log.Println(transaction.Exception)
+
+		return transaction
+	}
+
+	transaction.Complete(strategy, purchase.MarketPrice, purchase.Amount, 0)
+	log.Println(transaction)
+
+	err = i.history.Record(transaction)
+	if err != nil {
+		log.Printf("The %s stake could not be recorded in the history : %v", pair.Pair, err)
+	}
+
+	return transaction
+}
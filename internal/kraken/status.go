@@ -0,0 +1,73 @@
+package kraken
+
+//go:generate mockgen -destination=../mocks/mock_status_checker.go -package=mocks . StatusChecker
+
+import (
+	"errors"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"log"
+)
+
+type StatusChecker interface {
+	// Check Get the restrictions deferring the investment round, nil when the exchange and the pairs are online and
+	// the local clock is synchronized
+	Check() *domain.Deferral
+}
+
+type statusChecker struct {
+	exchange exchange.Exchange
+	pairs    []string
+	clock    Clock
+}
+
+func NewStatusChecker(exchange exchange.Exchange, pairs []string, clock Clock) StatusChecker {
+	return statusChecker{exchange: exchange, pairs: pairs, clock: clock}
+}
+
+// Check Check the local clock drift, the system status, when the exchange reports it, and the status of every pair.
+// A status that cannot be retrieved doesn't defer the round, its orders failing as usual if trading is actually
+// unavailable.
+func (s statusChecker) Check() *domain.Deferral {
+	drift, err := s.clock.Sync()
+	if err != nil && !errors.Is(err, ErrClockDrift) {
+		log.Printf("The clock drift cannot be checked : %v", err)
+	}
+
+	deferral := domain.Deferral{Date: s.clock.Now(), Pairs: map[string]string{}}
+	if errors.Is(err, ErrClockDrift) {
+		deferral.Drift = drift
+	}
+
+	if system, ok := s.exchange.(exchange.SystemStatus); ok {
+		status, err := system.SystemStatus()
+		if err != nil {
+			log.Printf("The system status cannot be retrieved : %v", err)
+		} else if status != exchange.Online {
+			deferral.System = status
+		}
+	}
+
+	// The pair statuses are unavailable during a maintenance
+	if deferral.System == exchange.Maintenance {
+		return &deferral
+	}
+
+	for _, pair := range s.pairs {
+		rules, err := s.exchange.Pair(pair)
+		if err != nil {
+			log.Printf("The %s status cannot be retrieved : %v", pair, err)
+			continue
+		}
+
+		if rules.Status != "" && rules.Status != exchange.Online {
+			deferral.Pairs[pair] = rules.Status
+		}
+	}
+
+	if deferral.System == "" && deferral.Drift == 0 && len(deferral.Pairs) == 0 {
+		return nil
+	}
+
+	return &deferral
+}
@@ -0,0 +1,97 @@
+package kraken
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/mocks"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatusCheckerOnline(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Pairs = map[string]*fake.Pair{
+		"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR"},
+		"XETHZEUR": {Base: "XETH", Quote: "ZEUR", Status: fake.Online},
+	}
+
+	checker := newStatusChecker(t, server, "XXBTZEUR", "XETHZEUR")
+	if deferral := checker.Check(); deferral != nil {
+		t.Errorf("The round should not be deferred : %v", deferral)
+	}
+}
+
+func TestStatusCheckerRestricted(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Status = fake.PostOnly
+	server.Pairs = map[string]*fake.Pair{
+		"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR"},
+		"XETHZEUR": {Base: "XETH", Quote: "ZEUR", Status: fake.CancelOnly},
+	}
+
+	checker := newStatusChecker(t, server, "XXBTZEUR", "XETHZEUR")
+	deferral := checker.Check()
+	if deferral == nil || deferral.System != fake.PostOnly || !reflect.DeepEqual(deferral.Pairs, map[string]string{"XETHZEUR": fake.CancelOnly}) {
+		t.Errorf("The deferral is %v", deferral)
+	}
+}
+
+func TestStatusCheckerMaintenance(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Status = fake.Maintenance
+	server.Pairs = map[string]*fake.Pair{"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR"}}
+
+	checker := newStatusChecker(t, server, "XXBTZEUR")
+	deferral := checker.Check()
+	if deferral == nil || deferral.System != fake.Maintenance || len(deferral.Pairs) != 0 {
+		t.Errorf("The deferral is %v", deferral)
+	}
+}
+
+func TestStatusCheckerUnavailableStatus(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// The exchange doesn't report its system status and the pair status cannot be retrieved
+	market := mocks.NewMockExchange(controller)
+	market.EXPECT().Pair("XXBTZEUR").Return(exchange.Pair{}, exchange.ErrUnsupported)
+	clock := mocks.NewMockClock(controller)
+	clock.EXPECT().Sync().Return(time.Duration(0), errors.New("the server time cannot be retrieved"))
+	clock.EXPECT().Now().Return(time.Now())
+
+	if deferral := NewStatusChecker(market, []string{"XXBTZEUR"}, clock).Check(); deferral != nil {
+		t.Errorf("The round should not be deferred : %v", deferral)
+	}
+}
+
+func TestStatusCheckerClockDrift(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.Now = func() time.Time {
+		return time.Now().Add(-2 * time.Minute)
+	}
+	server.Pairs = map[string]*fake.Pair{"XXBTZEUR": {Base: "XXBT", Quote: "ZEUR"}}
+
+	deferral := newStatusChecker(t, server, "XXBTZEUR").Check()
+	if deferral == nil || deferral.Drift > -119*time.Second || deferral.Drift < -121*time.Second || deferral.System != "" {
+		t.Errorf("The deferral is %v", deferral)
+	}
+}
+
+// newStatusChecker Get a status checker of the pairs on the fake server, its clock refusing a drift above 30 seconds
+func newStatusChecker(t *testing.T, server *fake.Server, pairs ...string) StatusChecker {
+	krakenExchange := NewExchange(NewApiWithClient("key", "c2VjcmV0", server.Client()))
+	clock, err := NewClock(krakenExchange, domain.Clock{WarnDrift: "5s", MaxDrift: "30s"})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	return NewStatusChecker(krakenExchange, pairs, clock)
+}
@@ -0,0 +1,102 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+func takeProfitKey(pair string) string {
+	return "takeprofit/" + pair
+}
+
+// takeProfit Execute the take-profit rungs of the pair crossed by the bid price, given the average cost basis of the
+// transaction history. Each rung sells its percentage of the holdings at the beginning of the round, is executed once
+// and is notified.
+func (i investingService) takeProfit(pair domain.DCAPair) []*domain.Transaction {
+	fail := func(err error) []*domain.Transaction {
+		transaction := domain.NewSellTransaction(pair.Pair)
+		transaction.Date = i.now()
+		transaction.Fail(fmt.Errorf("the %s take-profit ladder cannot be evaluated : %w", pair.Pair, err))
+		log.Println(transaction.Exception)
+
+		return []*domain.Transaction{transaction}
+	}
+
+	transactions, err := i.history.Transactions()
+	if err != nil {
+		return fail(fmt.Errorf("cannot load the transaction history : %w", err))
+	}
+
+	costBasis, ok := domain.CostBasis(transactions, pair.Pair)
+	if !ok {
+		log.Printf("[%s] Take-profit : no purchase recorded yet", pair.Pair)
+
+		return nil
+	}
+
+	price, err := i.tradingService.BidPrice(pair.Pair)
+	if err != nil {
+		return fail(err)
+	}
+
+	var executed []float64
+	_, err = i.state.Load(takeProfitKey(pair.Pair), &executed)
+	if err != nil {
+		return fail(err)
+	}
+
+	gain := domain.Gain(price, costBasis)
+	log.Printf("[%s] Take-profit : %.2f€ cost basis, %+.2f%% gain", pair.Pair, costBasis, gain)
+
+	rungs := pair.TakeProfit.Triggered(gain, executed)
+	if len(rungs) == 0 {
+		return nil
+	}
+
+	if price < costBasis && !pair.TakeProfit.BelowCost {
+		return nil
+	}
+
+	asset, err := i.tradingService.BaseAsset(pair.Pair)
+	if err != nil {
+		return fail(err)
+	}
+
+	holdings, err := i.accountService.Holdings(asset)
+	if err != nil {
+		return fail(err)
+	}
+
+	var sales []*domain.Transaction
+	for _, rung := range rungs {
+		rung := rung
+		transaction := domain.NewSellTransaction(pair.Pair)
+		transaction.Date = i.now()
+		transaction.Rung = &rung
+		ctx := context.WithValue(context.Background(), "transaction", transaction)
+		sales = append(sales, transaction)
+
+		err = i.placeSellOrder(ctx, pair.Pair, holdings*rung.Percentage/100)
+		if err != nil {
+			transaction.Fail(err)
+			continue
+		}
+
+		if transaction.Id != domain.StagedTransactionId {
+			executed = append(executed, rung.Gain)
+			err = i.state.Save(takeProfitKey(pair.Pair), executed)
+			if err != nil {
+				log.Printf("The %s take-profit progress could not be saved : %v", pair.Pair, err)
+			}
+		}
+
+		err = i.notifier.NotifyTakeProfit(transaction)
+		if err != nil {
+			log.Printf("The %s take-profit could not be notified : %v", pair.Pair, err)
+		}
+	}
+
+	return sales
+}
@@ -4,37 +4,59 @@ package kraken
 
 import (
 	"context"
-	"fmt"
 	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
 	"log"
-	"strconv"
+	"time"
 )
 
 type Trader interface {
 	PlaceOrder(ctx context.Context, pair domain.DCAPair) error
+	PlaceSellOrder(ctx context.Context, pair string, volume float64) error
 	Fee(pair string) (float64, error)
 	AskPrice(pair string) (float64, error)
+	BidPrice(pair string) (float64, error)
+	BaseAsset(pair string) (string, error)
+	Candles(pair string, interval int) ([]domain.Candle, error)
+	// Staging Get whether the orders are only validated
+	Staging() bool
+}
+
+// CandleSource serves the OHLC history of the pairs, `interval` being the candle duration in minutes
+type CandleSource interface {
+	Candles(pair string, interval int) ([]domain.Candle, error)
 }
 
 type tradingService struct {
-	api     ApiInterface
+	exchange exchange.Exchange
+	// candles serves the price history of the strategies, the exchange by default
+	candles CandleSource
 	staging bool
 }
 
-func NewTrader(api ApiInterface, staging bool) Trader {
+// orderExpiry is the duration after which an unfilled order is canceled
+const orderExpiry = 5 * time.Minute
+
+func NewTrader(exchange exchange.Exchange, staging bool) Trader {
+	return NewTraderWithCandles(exchange, staging, exchange)
+}
+
+// NewTraderWithCandles Get a trader reading the price history from the `candles` source, e.g. a local market data store
+func NewTraderWithCandles(exchange exchange.Exchange, staging bool, candles CandleSource) Trader {
 	return &tradingService{
-		api:     api,
-		staging: staging,
+		exchange: exchange,
+		candles:  candles,
+		staging:  staging,
 	}
 }
 
+func (t tradingService) Staging() bool {
+	return t.staging
+}
+
 // Fee Get fee percentage for the given pair
 func (t tradingService) Fee(pair string) (float64, error) {
-	tradeVolume, err := t.api.Query("TradeVolume", map[string]string{"pair": pair, "fee-info": "true"})
-	if err != nil {
-		return -1, err
-	}
-	feePercentage, err := strconv.ParseFloat(extractData(tradeVolume, "fees", pair, "fee").(string), 64)
+	feePercentage, err := t.exchange.Fee(pair)
 	if err != nil {
 		return -1, err
 	}
@@ -46,21 +68,41 @@ func (t tradingService) Fee(pair string) (float64, error) {
 
 // AskPrice Get the latest ticker information
 func (t tradingService) AskPrice(pair string) (float64, error) {
-	ticker, err := t.api.Query("Ticker", map[string]string{
-		"pair": pair,
-	})
+	ticker, err := t.exchange.Ticker(pair)
 	if err != nil {
 		return -1, err
 	}
 
-	askPrice, err := strconv.ParseFloat(extractData(ticker, pair, "a").([]interface{})[0].(string), 64)
+	log.Printf("[%s] Ask price : %.2f€", pair, ticker.Ask)
+
+	return ticker.Ask, nil
+}
+
+// BidPrice Get the latest price buyers are willing to pay for the pair, i.e. the price assets are sold at
+func (t tradingService) BidPrice(pair string) (float64, error) {
+	ticker, err := t.exchange.Ticker(pair)
 	if err != nil {
 		return -1, err
 	}
 
-	log.Printf("[%s] Ask price : %.2f€", pair, askPrice)
+	log.Printf("[%s] Bid price : %.2f€", pair, ticker.Bid)
 
-	return askPrice, nil
+	return ticker.Bid, nil
+}
+
+// BaseAsset Get the asset bought when trading the given pair (e.g. XXBT for XXBTZEUR)
+func (t tradingService) BaseAsset(pair string) (string, error) {
+	rules, err := t.exchange.Pair(pair)
+	if err != nil {
+		return "", err
+	}
+
+	return rules.Base, nil
+}
+
+// Candles Get the OHLC history of the given pair, `interval` being the candle duration in minutes
+func (t tradingService) Candles(pair string, interval int) ([]domain.Candle, error) {
+	return t.candles.Candles(pair, interval)
 }
 
 // PlaceOrder Place an order for the given pair.
@@ -82,29 +124,59 @@ func (t tradingService) PlaceOrder(ctx context.Context, pair domain.DCAPair) err
 	orderVolume := pair.Amount / askPrice
 	fee := orderVolume * feePercentage / 100
 	orderVolume = orderVolume - fee
-	order, err := t.api.AddOrder(pair.Pair, "buy", "market", fmt.Sprintf("%f", orderVolume), map[string]string{
-		"expiretm": "+300",
-		"validate": strconv.FormatBool(t.staging),
-	})
+	id, err := t.place(pair.Pair, exchange.Buy, orderVolume)
 	if err != nil {
 		return err
 	}
 
-	orderTransactionId := "STAGED"
-	if !t.staging {
-		orderTransactionId = order.TransactionIds[0]
+	transaction.Complete(id, askPrice, orderVolume, fee)
+
+	return nil
+}
+
+// PlaceSellOrder Place an order selling `volume` of the pair base asset at the market price.
+// The fee is deducted from the proceeds and recorded in base asset units, like for buy orders.
+// The `ctx` context contains the transaction to update at the "transaction" key.
+func (t tradingService) PlaceSellOrder(ctx context.Context, pair string, volume float64) error {
+	transaction := ctx.Value("transaction").(*domain.Transaction)
+
+	feePercentage, err := t.Fee(pair)
+	if err != nil {
+		return err
 	}
 
-	transaction.Complete(orderTransactionId, askPrice, orderVolume, fee)
+	bidPrice, err := t.BidPrice(pair)
+	if err != nil {
+		return err
+	}
+
+	fee := volume * feePercentage / 100
+	id, err := t.place(pair, exchange.Sell, volume)
+	if err != nil {
+		return err
+	}
+
+	transaction.Complete(id, bidPrice, volume, fee)
 
 	return nil
 }
 
-func extractData(data interface{}, fields ...string) interface{} {
-	fieldData := data
-	for _, field := range fields {
-		fieldData = fieldData.(map[string]interface{})[field]
+// place Place a market order and get its id, orders being only validated in staging
+func (t tradingService) place(pair string, side string, volume float64) (string, error) {
+	id, err := t.exchange.PlaceOrder(exchange.Order{
+		Pair:     pair,
+		Side:     side,
+		Volume:   volume,
+		Validate: t.staging,
+		Expiry:   orderExpiry,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if t.staging {
+		return domain.StagedTransactionId, nil
 	}
 
-	return fieldData
+	return id, nil
 }
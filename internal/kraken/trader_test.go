@@ -9,6 +9,7 @@ import (
 	"kraken-dca-bot/internal/mocks"
 	"strconv"
 	"testing"
+	"time"
 )
 
 var krakenApi *mocks.MockApiInterface
@@ -18,7 +19,7 @@ func setup(t *testing.T) func() {
 	controller := gomock.NewController(t)
 
 	krakenApi = mocks.NewMockApiInterface(controller)
-	service = NewTrader(krakenApi, false)
+	service = NewTrader(NewExchange(krakenApi), false)
 
 	return controller.Finish
 }
@@ -112,6 +113,7 @@ func TestAskPriceSuccess(t *testing.T) {
 		map[string]interface{}{
 			"TESTPAIR": map[string]interface{}{
 				"a": []interface{}{"1545.89"},
+				"b": []interface{}{"1545.12"},
 			},
 		},
 		nil)
@@ -156,6 +158,7 @@ func TestAskPriceParseFail(t *testing.T) {
 		map[string]interface{}{
 			"TESTPAIR": map[string]interface{}{
 				"a": []interface{}{"abc"},
+				"b": []interface{}{"1545.12"},
 			},
 		},
 		nil)
@@ -171,6 +174,129 @@ func TestAskPriceParseFail(t *testing.T) {
 	}
 }
 
+// BidPrice method tests
+
+func TestBidPriceSuccess(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"Ticker",
+		map[string]string{"pair": "TESTPAIR"},
+	).Return(
+		map[string]interface{}{
+			"TESTPAIR": map[string]interface{}{
+				"a": []interface{}{"1545.89"},
+				"b": []interface{}{"1545.12"},
+			},
+		},
+		nil)
+
+	bidPrice, err := service.BidPrice("TESTPAIR")
+	if err != nil {
+		t.Errorf("An unexpected bid price error occured : %v", err)
+	}
+
+	if bidPrice != 1545.12 {
+		t.Errorf("Bid price is equal to %f instead of 1545.12", bidPrice)
+	}
+}
+
+// BaseAsset method tests
+
+func TestBaseAssetSuccess(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"AssetPairs",
+		map[string]string{"pair": "TESTPAIR"},
+	).Return(
+		map[string]interface{}{
+			"TESTPAIR": map[string]interface{}{
+				"base":  "XTEST",
+				"quote": "ZEUR",
+			},
+		},
+		nil)
+
+	asset, err := service.BaseAsset("TESTPAIR")
+	if err != nil {
+		t.Errorf("An unexpected base asset error occured : %v", err)
+	}
+
+	if asset != "XTEST" {
+		t.Errorf("Base asset is equal to %v instead of XTEST", asset)
+	}
+}
+
+func TestBaseAssetFail(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"AssetPairs",
+		map[string]string{"pair": "TESTPAIR"},
+	).Return(nil, errors.New("asset pairs error"))
+
+	_, err := service.BaseAsset("TESTPAIR")
+	if err == nil || err.Error() != "asset pairs error" {
+		t.Errorf("No relevant base asset error occured : %v", err)
+	}
+}
+
+// Candles method tests
+
+func TestCandlesSuccess(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"OHLC",
+		map[string]string{"pair": "TESTPAIR", "interval": "1440"},
+	).Return(
+		map[string]interface{}{
+			"TESTPAIR": []interface{}{
+				[]interface{}{float64(1664150400), "18000.1", "19500.0", "17800.2", "19000.4", "18900.3", "120.5", float64(4200)},
+				[]interface{}{float64(1664236800), "19000.4", "20100.0", "18700.0", "19800.9", "19500.1", "98.2", float64(3900)},
+			},
+			"last": float64(1664236800),
+		},
+		nil)
+
+	candles, err := service.Candles("TESTPAIR", 1440)
+	if err != nil {
+		t.Errorf("An unexpected candles error occured : %v", err)
+	}
+
+	expected := domain.Candle{
+		Time:   time.Unix(1664236800, 0),
+		Open:   19000.4,
+		High:   20100.0,
+		Low:    18700.0,
+		Close:  19800.9,
+		Volume: 98.2,
+	}
+	if len(candles) != 2 || candles[1] != expected {
+		t.Errorf("Candles are equal to %v", candles)
+	}
+}
+
+func TestCandlesFail(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"OHLC",
+		map[string]string{"pair": "TESTPAIR", "interval": "1440"},
+	).Return(nil, errors.New("ohlc error"))
+
+	_, err := service.Candles("TESTPAIR", 1440)
+	if err == nil || err.Error() != "ohlc error" {
+		t.Errorf("No relevant candles error occured : %v", err)
+	}
+}
+
 // PlaceOrder method tests
 func TestPlaceOrderSuccess(t *testing.T) {
 	cleanUp := setup(t)
@@ -196,6 +322,7 @@ func TestPlaceOrderSuccess(t *testing.T) {
 		map[string]interface{}{
 			"TESTPAIR": map[string]interface{}{
 				"a": []interface{}{"1545.89"},
+				"b": []interface{}{"1545.12"},
 			},
 		},
 		nil)
@@ -322,6 +449,7 @@ func TestPlaceOrderFail(t *testing.T) {
 		map[string]interface{}{
 			"TESTPAIR": map[string]interface{}{
 				"a": []interface{}{"1545.89"},
+				"b": []interface{}{"1545.12"},
 			},
 		},
 		nil)
@@ -348,3 +476,73 @@ func TestPlaceOrderFail(t *testing.T) {
 		t.Errorf("An unexpected error has been raised : %v", err)
 	}
 }
+
+// PlaceSellOrder method tests
+
+func TestPlaceSellOrderSuccess(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"TradeVolume",
+		map[string]string{"pair": "TESTPAIR", "fee-info": "true"},
+	).Return(
+		map[string]interface{}{
+			"fees": map[string]interface{}{
+				"TESTPAIR": map[string]interface{}{
+					"fee": "0.25",
+				},
+			},
+		},
+		nil)
+
+	krakenApi.EXPECT().Query(
+		"Ticker",
+		map[string]string{"pair": "TESTPAIR"},
+	).Return(
+		map[string]interface{}{
+			"TESTPAIR": map[string]interface{}{
+				"a": []interface{}{"2001"},
+				"b": []interface{}{"2000"},
+			},
+		},
+		nil)
+
+	krakenApi.EXPECT().AddOrder(
+		"TESTPAIR",
+		"sell",
+		"market",
+		"0.100000",
+		map[string]string{
+			"expiretm": "+300",
+			"validate": "false",
+		},
+	).Return(&krakenapi.AddOrderResponse{TransactionIds: []string{"ID"}}, nil)
+
+	transaction := domain.NewSellTransaction("TESTPAIR")
+	ctx := context.WithValue(context.Background(), "transaction", transaction)
+	err := service.PlaceSellOrder(ctx, "TESTPAIR", 0.1)
+	if err != nil {
+		t.Errorf("An unexpected error has been raised : %v", err)
+	}
+
+	if transaction.Id != "ID" || transaction.MarketPrice != 2000 || transaction.Amount != 0.1 || transaction.Fee != 0.00025 {
+		t.Errorf("The sell transaction is %v", transaction)
+	}
+}
+
+func TestPlaceSellOrderFail(t *testing.T) {
+	cleanUp := setup(t)
+	defer cleanUp()
+
+	krakenApi.EXPECT().Query(
+		"TradeVolume",
+		map[string]string{"pair": "TESTPAIR", "fee-info": "true"},
+	).Return(nil, errors.New("fee error"))
+
+	ctx := context.WithValue(context.Background(), "transaction", domain.NewSellTransaction("TESTPAIR"))
+	err := service.PlaceSellOrder(ctx, "TESTPAIR", 0.1)
+	if err == nil || err.Error() != "fee error" {
+		t.Errorf("No relevant error has been raised : %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package kraken
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"log"
+)
+
+// valueAveragingAmount Get the amount needed for the pair holdings value to reach its value averaging target.
+// The holdings are valued at the current ask price of the pair.
+func (i investingService) valueAveragingAmount(pair domain.DCAPair) (float64, error) {
+	period, err := i.config.Period()
+	if err != nil {
+		return -1, fmt.Errorf("cannot parse the DCA frequency : %w", err)
+	}
+
+	asset, err := i.tradingService.BaseAsset(pair.Pair)
+	if err != nil {
+		return -1, fmt.Errorf("cannot get the %s base asset : %w", pair.Pair, err)
+	}
+
+	holdings, err := i.accountService.Holdings(asset)
+	if err != nil {
+		return -1, fmt.Errorf("cannot get the %s holdings : %w", asset, err)
+	}
+
+	askPrice, err := i.tradingService.AskPrice(pair.Pair)
+	if err != nil {
+		return -1, fmt.Errorf("cannot get the %s ask price : %w", pair.Pair, err)
+	}
+
+	value := holdings * askPrice
+	target := pair.ValueAveraging.Target(i.now(), period)
+	amount := pair.ValueAveraging.Amount(target, value)
+	log.Printf("[%s] Holdings value : %.2f€ - Target : %.2f€ - Amount : %.2f€", pair.Pair, value, target, amount)
+
+	if amount <= 0 {
+		return 0, skipError{reason: fmt.Sprintf("the holdings value %.2f is ahead of the %.2f target", value, target)}
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,127 @@
+package kraken
+
+//go:generate mockgen -destination=../mocks/mock_withdrawer.go -package=mocks . Withdrawer
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/storage"
+	"log"
+	"time"
+)
+
+type Withdrawer interface {
+	// Sweep Withdraw the holdings exceeding their policy threshold and get the withdrawal attempts
+	Sweep() []*domain.Withdrawal
+}
+
+type withdrawer struct {
+	config         domain.Withdrawals
+	accountService Account
+	notifier       notify.Notifier
+	state          storage.State
+	now            func() time.Time
+}
+
+func withdrawalKey(asset string) string {
+	return "withdrawal/" + asset
+}
+
+// NewWithdrawer Get a withdrawer timing the policy intervals with the `now` clock
+func NewWithdrawer(config domain.Withdrawals, accountService Account, notifier notify.Notifier, state storage.State, now func() time.Time) Withdrawer {
+	return withdrawer{
+		config:         config,
+		accountService: accountService,
+		notifier:       notifier,
+		state:          state,
+		now:            now,
+	}
+}
+
+// Sweep Evaluate the withdrawal policies : the holdings above a policy threshold are withdrawn to its key, the amount
+// left behind excepted, unless the last withdrawal of the asset is more recent than the policy interval.
+// The withdrawal fee is checked before withdrawing, and every attempt, dry-run ones included, is notified. Only the
+// real withdrawals start the policy interval.
+func (w withdrawer) Sweep() []*domain.Withdrawal {
+	var withdrawals []*domain.Withdrawal
+	now := w.now()
+
+	for _, policy := range w.config.Policies {
+		due, err := w.due(policy, now)
+		if err != nil {
+			log.Printf("The %s withdrawal policy is ignored : %v", policy.Asset, err)
+			continue
+		}
+		if !due {
+			continue
+		}
+
+		holdings, err := w.accountService.Holdings(policy.Asset)
+		if err != nil {
+			log.Printf("The %s withdrawal policy is ignored, the holdings cannot be collected : %v", policy.Asset, err)
+			continue
+		}
+
+		amount, ok := policy.Amount(holdings)
+		if !ok {
+			continue
+		}
+
+		withdrawal := w.withdraw(policy, amount)
+		log.Println(withdrawal)
+		withdrawals = append(withdrawals, withdrawal)
+
+		// The dry-run attempts don't delay the real withdrawals
+		if withdrawal.Exception == nil && !w.config.DryRun {
+			err = w.state.Save(withdrawalKey(policy.Asset), now)
+			if err != nil {
+				log.Printf("The %s withdrawal date could not be saved : %v", policy.Asset, err)
+			}
+		}
+
+		err = w.notifier.NotifyWithdrawal(withdrawal)
+		if err != nil {
+			log.Printf("The %s withdrawal could not be notified : %v", policy.Asset, err)
+		}
+	}
+
+	return withdrawals
+}
+
+// due Get whether the policy interval has elapsed since the last withdrawal of the asset
+func (w withdrawer) due(policy domain.WithdrawalPolicy, now time.Time) (bool, error) {
+	interval, err := policy.MinInterval()
+	if err != nil {
+		return false, err
+	}
+
+	var last time.Time
+	found, err := w.state.Load(withdrawalKey(policy.Asset), &last)
+	if err != nil {
+		return false, err
+	}
+
+	return !found || now.Sub(last) >= interval, nil
+}
+
+func (w withdrawer) withdraw(policy domain.WithdrawalPolicy, amount float64) *domain.Withdrawal {
+	withdrawal := domain.NewWithdrawal(policy, amount, w.config.DryRun)
+
+	fee, err := w.accountService.WithdrawalFee(policy.Asset, policy.Key, amount)
+	if err != nil {
+		return withdrawal.Fail(fmt.Errorf("the %s withdrawal was refused : %w", policy.Asset, err))
+	}
+	withdrawal.Fee = fee
+
+	if w.config.DryRun {
+		return withdrawal
+	}
+
+	withdrawal.RefId, err = w.accountService.Withdraw(policy.Asset, policy.Key, amount)
+	if err != nil {
+		return withdrawal.Fail(fmt.Errorf("the %s withdrawal failed : %w", policy.Asset, err))
+	}
+
+	return withdrawal
+}
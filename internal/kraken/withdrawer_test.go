@@ -0,0 +1,95 @@
+package kraken
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/mocks"
+	"testing"
+	"time"
+)
+
+var withdrawals = domain.Withdrawals{
+	Keys: []string{"ledger-btc", "ledger-eth"},
+	Policies: []domain.WithdrawalPolicy{
+		{Asset: "XXBT", Key: "ledger-btc", Threshold: 0.1, Leave: 0.01, Interval: "7d"},
+		{Asset: "XETH", Key: "ledger-eth", Threshold: 1, Interval: "7d"},
+	},
+}
+
+func TestWithdrawerSweep(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	state := mocks.NewMockState(controller)
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	withdrawer := NewWithdrawer(withdrawals, accountService, notifier, state, func() time.Time { return now })
+
+	state.EXPECT().Load("withdrawal/XXBT", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*time.Time) = now.Add(-8 * 24 * time.Hour)
+
+		return true, nil
+	})
+	accountService.EXPECT().Holdings("XXBT").Return(0.25, nil)
+	accountService.EXPECT().WithdrawalFee("XXBT", "ledger-btc", 0.24).Return(0.0005, nil)
+	accountService.EXPECT().Withdraw("XXBT", "ledger-btc", 0.24).Return("REF", nil)
+	state.EXPECT().Save("withdrawal/XXBT", now).Return(nil)
+	state.EXPECT().Load("withdrawal/XETH", gomock.Any()).Return(false, nil)
+	accountService.EXPECT().Holdings("XETH").Return(0.5, nil)
+	notifier.EXPECT().NotifyWithdrawal(gomock.Any()).Return(nil)
+
+	sweep := withdrawer.Sweep()
+
+	if len(sweep) != 1 || sweep[0].RefId != "REF" || sweep[0].Amount != 0.24 || sweep[0].Fee != 0.0005 || sweep[0].Exception != nil {
+		t.Errorf("The withdrawals are %v", sweep)
+	}
+}
+
+func TestWithdrawerSweepIntervalNotElapsed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	state := mocks.NewMockState(controller)
+	withdrawer := NewWithdrawer(domain.Withdrawals{Keys: withdrawals.Keys, Policies: withdrawals.Policies[:1]}, mocks.NewMockAccount(controller), mocks.NewMockNotifier(controller), state, time.Now)
+
+	state.EXPECT().Load("withdrawal/XXBT", gomock.Any()).DoAndReturn(func(key string, value interface{}) (bool, error) {
+		*value.(*time.Time) = time.Now().Add(-24 * time.Hour)
+
+		return true, nil
+	})
+
+	if sweep := withdrawer.Sweep(); len(sweep) != 0 {
+		t.Errorf("No withdrawal should be attempted : %v", sweep)
+	}
+}
+
+func TestWithdrawerSweepDryRun(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	accountService := mocks.NewMockAccount(controller)
+	notifier := mocks.NewMockNotifier(controller)
+	state := mocks.NewMockState(controller)
+	withdrawer := NewWithdrawer(domain.Withdrawals{DryRun: true, Keys: withdrawals.Keys, Policies: withdrawals.Policies}, accountService, notifier, state, time.Now)
+
+	state.EXPECT().Load(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+	accountService.EXPECT().Holdings("XXBT").Return(0.25, nil)
+	accountService.EXPECT().WithdrawalFee("XXBT", "ledger-btc", 0.24).Return(0.0005, nil)
+	accountService.EXPECT().Holdings("XETH").Return(2.0, nil)
+	accountService.EXPECT().WithdrawalFee("XETH", "ledger-eth", 2.0).Return(-1.0, errors.New("unknown key"))
+	accountService.EXPECT().Withdraw(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	state.EXPECT().Save(gomock.Any(), gomock.Any()).Times(0)
+	notifier.EXPECT().NotifyWithdrawal(gomock.Any()).Return(nil).Times(2)
+
+	sweep := withdrawer.Sweep()
+
+	if len(sweep) != 2 || !sweep[0].DryRun || sweep[0].RefId != "" || sweep[0].Exception != nil {
+		t.Errorf("The dry-run withdrawals are %v", sweep)
+	}
+
+	if sweep[1].Exception == nil || sweep[1].Exception.Error() != "the XETH withdrawal was refused : unknown key" {
+		t.Errorf("The XETH withdrawal should fail : %v", sweep[1].Exception)
+	}
+}
@@ -0,0 +1,39 @@
+package marketdata
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"time"
+)
+
+// StoreCandles serves the candles of the store, downloading the latest ones from the exchange beforehand so that the
+// strategies get a longer price history than the exchanges serve
+type StoreCandles struct {
+	store      Store
+	downloader Downloader
+}
+
+// NewStoreCandles Get the candles of the store kept up to date from the market, which must serve its history
+func NewStoreCandles(market exchange.Exchange, store Store) (StoreCandles, error) {
+	history, ok := market.(exchange.MarketHistory)
+	if !ok {
+		return StoreCandles{}, fmt.Errorf("the market history cannot be downloaded : %w", exchange.ErrUnsupported)
+	}
+
+	return StoreCandles{
+		store:      store,
+		downloader: NewDownloader(history, store, 0),
+	}, nil
+}
+
+// Candles Get the stored OHLC history of the given pair, `interval` being the candle duration in minutes, after
+// downloading the candles started since the latest stored one
+func (c StoreCandles) Candles(pair string, interval int) ([]domain.Candle, error) {
+	_, err := c.downloader.DownloadCandles(pair, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.store.Candles(pair, interval, time.Time{}, time.Time{})
+}
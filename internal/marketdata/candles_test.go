@@ -0,0 +1,40 @@
+package marketdata
+
+import (
+	"errors"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/kraken"
+	"testing"
+)
+
+func TestStoreCandles(t *testing.T) {
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	server.Pairs["XXBTZEUR"] = &fake.Pair{Base: "XXBT", Quote: "ZEUR"}
+
+	// The stored history is longer than the one still served by the exchange
+	store := NewFileStore(t.TempDir())
+	err := store.SaveCandles("XXBTZEUR", 1440, dailyCandles(january, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Pairs["XXBTZEUR"].Candles = dailyCandles(january, 7)[4:]
+
+	candles, err := NewStoreCandles(kraken.NewExchange(kraken.NewApiWithClient("", "", server.Client())), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := candles.Candles("XXBTZEUR", 1440)
+	if err != nil || len(history) != 7 || !history[0].Time.Equal(january) {
+		t.Errorf("The candles are %v (%v)", history, err)
+	}
+}
+
+func TestStoreCandlesUnsupported(t *testing.T) {
+	_, err := NewStoreCandles(nil, NewFileStore(t.TempDir()))
+	if !errors.Is(err, exchange.ErrUnsupported) {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
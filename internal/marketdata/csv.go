@@ -0,0 +1,154 @@
+package marketdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"kraken-dca-bot/internal/domain"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// LoadCsv Read the candles of a CSV file, oldest first. Each line holds the candle time as a Unix timestamp, then
+// its open, high, low and close prices and its volume, like the Kraken OHLCVT exports. Extra columns and a header line
+// are ignored.
+func LoadCsv(path string) ([]domain.Candle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("the candles file cannot be opened : %w", err)
+	}
+	defer file.Close()
+
+	return ReadCsv(file)
+}
+
+// ReadCsv Read the candles of CSV content, see LoadCsv
+func ReadCsv(reader io.Reader) ([]domain.Candle, error) {
+	lines := csv.NewReader(reader)
+	lines.FieldsPerRecord = -1
+
+	var candles []domain.Candle
+	for line := 1; ; line++ {
+		record, err := lines.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the candles line %d cannot be read : %w", line, err)
+		}
+
+		if len(record) < 6 {
+			return nil, fmt.Errorf("the candles line %d has %d columns instead of 6", line, len(record))
+		}
+
+		timestamp, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil && line == 1 {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the candles line %d time is invalid : %w", line, err)
+		}
+
+		values := make([]float64, 5)
+		for index := range values {
+			values[index], err = strconv.ParseFloat(record[index+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("the candles line %d column %d is invalid : %w", line, index+2, err)
+			}
+		}
+
+		candles = append(candles, domain.Candle{
+			Time:   time.Unix(timestamp, 0),
+			Open:   values[0],
+			High:   values[1],
+			Low:    values[2],
+			Close:  values[3],
+			Volume: values[4],
+		})
+	}
+
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Time.Before(candles[j].Time)
+	})
+
+	return candles, nil
+}
+
+// WriteCsv Write the candles in the LoadCsv format
+func WriteCsv(writer io.Writer, candles []domain.Candle) error {
+	lines := csv.NewWriter(writer)
+	for _, candle := range candles {
+		err := lines.Write([]string{
+			strconv.FormatInt(candle.Time.Unix(), 10),
+			formatFloat(candle.Open),
+			formatFloat(candle.High),
+			formatFloat(candle.Low),
+			formatFloat(candle.Close),
+			formatFloat(candle.Volume),
+		})
+		if err != nil {
+			return fmt.Errorf("the candles cannot be written : %w", err)
+		}
+	}
+	lines.Flush()
+
+	return lines.Error()
+}
+
+// readTrades Read the trades of CSV content, each line holding the trade time as a Unix timestamp in nanoseconds, then
+// its price, volume and side
+func readTrades(reader io.Reader) ([]domain.Trade, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("the trades cannot be read : %w", err)
+	}
+
+	trades := make([]domain.Trade, len(records))
+	for index, record := range records {
+		if len(record) != 4 {
+			return nil, fmt.Errorf("the trades line %d has %d columns instead of 4", index+1, len(record))
+		}
+
+		nanoseconds, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the trades line %d time is invalid : %w", index+1, err)
+		}
+		price, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("the trades line %d price is invalid : %w", index+1, err)
+		}
+		volume, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("the trades line %d volume is invalid : %w", index+1, err)
+		}
+
+		trades[index] = domain.Trade{Time: time.Unix(0, nanoseconds), Price: price, Volume: volume, Side: record[3]}
+	}
+
+	return trades, nil
+}
+
+// writeTrades Write the trades in the readTrades format
+func writeTrades(writer io.Writer, trades []domain.Trade) error {
+	lines := csv.NewWriter(writer)
+	for _, trade := range trades {
+		err := lines.Write([]string{
+			strconv.FormatInt(trade.Time.UnixNano(), 10),
+			formatFloat(trade.Price),
+			formatFloat(trade.Volume),
+			trade.Side,
+		})
+		if err != nil {
+			return fmt.Errorf("the trades cannot be written : %w", err)
+		}
+	}
+	lines.Flush()
+
+	return lines.Error()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,44 @@
+package marketdata
+
+import (
+	"bytes"
+	"kraken-dca-bot/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCsv(t *testing.T) {
+	content := "time,open,high,low,close,volume,trades\n1640995200,100,110,90,105,12.5,40\n1640908800,95,101,94,100,10,30\n"
+
+	candles, err := ReadCsv(strings.NewReader(content))
+	if err != nil || len(candles) != 2 {
+		t.Fatalf("The candles are %v (%v)", candles, err)
+	}
+
+	if candles[0].Time.Unix() != 1640908800 || candles[1].Open != 100 || candles[1].Close != 105 || candles[1].Volume != 12.5 {
+		t.Errorf("The candles are %v", candles)
+	}
+
+	_, err = ReadCsv(strings.NewReader("1640995200,100,110,90\n"))
+	if err == nil || err.Error() != "the candles line 1 has 4 columns instead of 6" {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
+
+func TestWriteCsv(t *testing.T) {
+	candles := []domain.Candle{
+		{Time: time.Unix(1640908800, 0), Open: 95, High: 101, Low: 94.5, Close: 100, Volume: 10.125},
+	}
+
+	var content bytes.Buffer
+	err := WriteCsv(&content, candles)
+	if err != nil || content.String() != "1640908800,95,101,94.5,100,10.125\n" {
+		t.Fatalf("The written candles are %s (%v)", content.String(), err)
+	}
+
+	read, err := ReadCsv(&content)
+	if err != nil || len(read) != 1 || read[0] != candles[0] {
+		t.Errorf("The read candles are %v (%v)", read, err)
+	}
+}
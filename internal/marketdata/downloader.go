@@ -0,0 +1,110 @@
+package marketdata
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/exchange"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Downloader pulls the market history of the pairs from an exchange into a store, resuming after the stored data
+type Downloader struct {
+	history exchange.MarketHistory
+	store   Store
+	// pause is the delay between two trades requests, to stay below the exchange rate limit
+	pause time.Duration
+}
+
+func NewDownloader(history exchange.MarketHistory, store Store, pause time.Duration) Downloader {
+	return Downloader{
+		history: history,
+		store:   store,
+		pause:   pause,
+	}
+}
+
+// DownloadCandles Store the candles started since the latest stored one, which is replaced as it may have been running
+// when downloaded. Get the number of candles downloaded.
+func (d Downloader) DownloadCandles(pair string, interval int) (int, error) {
+	last, found, err := d.store.LastCandle(pair, interval)
+	if err != nil {
+		return 0, fmt.Errorf("the latest %s candle cannot be read : %w", pair, err)
+	}
+
+	since := time.Time{}
+	if found {
+		// The exchange serves the candles started after `since`
+		since = last.Add(-time.Second)
+	}
+
+	candles, err := d.history.CandlesSince(pair, interval, since)
+	if err != nil {
+		return 0, fmt.Errorf("the %s candles cannot be downloaded : %w", pair, err)
+	}
+
+	// The exchanges only serve the latest candles, those between the stored ones and the downloaded ones are lost
+	if found && len(candles) > 0 {
+		if missing := missingCandles(last, candles[0].Time, interval); missing > 0 {
+			log.Printf("[%s] %d candles of %d minutes are missing from the store after %s, the exchange doesn't serve them anymore", pair, missing, interval, last.Format(time.RFC3339))
+		}
+	}
+
+	err = d.store.SaveCandles(pair, interval, candles)
+	if err != nil {
+		return 0, fmt.Errorf("the %s candles cannot be stored : %w", pair, err)
+	}
+	log.Printf("[%s] %d candles of %d minutes downloaded", pair, len(candles), interval)
+
+	return len(candles), nil
+}
+
+// missingCandles Get the number of candles of `interval` minutes started between the `last` stored one and the `next`
+// downloaded one
+func missingCandles(last time.Time, next time.Time, interval int) int {
+	missing := int(next.Sub(last)/(time.Duration(interval)*time.Minute)) - 1
+	if missing < 0 {
+		return 0
+	}
+
+	return missing
+}
+
+// DownloadTrades Store the trades following the stored ones, or executed since `since` if none is stored, until the
+// download start. Get the number of trades downloaded.
+func (d Downloader) DownloadTrades(pair string, since time.Time) (int, error) {
+	cursor, err := d.store.TradesCursor(pair)
+	if err != nil {
+		return 0, err
+	}
+	if cursor == "" && !since.IsZero() {
+		cursor = strconv.FormatInt(since.UnixNano(), 10)
+	}
+
+	start := time.Now()
+	count := 0
+	for {
+		trades, next, err := d.history.Trades(pair, cursor)
+		if err != nil {
+			return count, fmt.Errorf("the %s trades cannot be downloaded : %w", pair, err)
+		}
+
+		if len(trades) == 0 || next == cursor {
+			return count, nil
+		}
+
+		err = d.store.SaveTrades(pair, trades, next)
+		if err != nil {
+			return count, fmt.Errorf("the %s trades cannot be stored : %w", pair, err)
+		}
+		count += len(trades)
+		cursor = next
+		log.Printf("[%s] %d trades downloaded, up to %s", pair, count, trades[len(trades)-1].Time.Format(time.RFC3339))
+
+		if !trades[len(trades)-1].Time.Before(start) {
+			return count, nil
+		}
+
+		time.Sleep(d.pause)
+	}
+}
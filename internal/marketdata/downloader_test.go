@@ -0,0 +1,114 @@
+package marketdata
+
+import (
+	"errors"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/kraken"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupDownloader Get a downloader from a fake Kraken server into a temporary store
+func setupDownloader(t *testing.T) (*fake.Server, Store, Downloader) {
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	server.Pairs["XXBTZEUR"] = &fake.Pair{Base: "XXBT", Quote: "ZEUR"}
+
+	history, ok := kraken.NewExchange(kraken.NewApiWithClient("", "", server.Client())).(exchange.MarketHistory)
+	if !ok {
+		t.Fatal("The Kraken exchange doesn't serve the market history")
+	}
+
+	store := NewFileStore(t.TempDir())
+
+	return server, store, NewDownloader(history, store, 0)
+}
+
+func TestDownloadCandles(t *testing.T) {
+	server, store, downloader := setupDownloader(t)
+	server.Pairs["XXBTZEUR"].Candles = dailyCandles(january, 3)
+
+	count, err := downloader.DownloadCandles("XXBTZEUR", 1440)
+	if err != nil || count != 3 {
+		t.Fatalf("%d candles were downloaded (%v)", count, err)
+	}
+
+	// The download resumes from the last stored candle, which was running
+	server.Pairs["XXBTZEUR"].Candles = dailyCandles(january, 5)
+	server.Pairs["XXBTZEUR"].Candles[2].Close = 150
+
+	count, err = downloader.DownloadCandles("XXBTZEUR", 1440)
+	if err != nil || count != 3 {
+		t.Fatalf("%d candles were downloaded (%v)", count, err)
+	}
+
+	candles, err := store.Candles("XXBTZEUR", 1440, time.Time{}, time.Time{})
+	if err != nil || len(candles) != 5 || candles[2].Close != 150 {
+		t.Errorf("The stored candles are %v (%v)", candles, err)
+	}
+}
+
+func TestMissingCandles(t *testing.T) {
+	cases := []struct {
+		days    int
+		missing int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{722, 721},
+	}
+
+	for _, c := range cases {
+		missing := missingCandles(january, january.AddDate(0, 0, c.days), 1440)
+		if missing != c.missing {
+			t.Errorf("%d candles are missing after %d days instead of %d", missing, c.days, c.missing)
+		}
+	}
+}
+
+func TestDownloadTrades(t *testing.T) {
+	server, store, downloader := setupDownloader(t)
+
+	trades := make([]domain.Trade, 2500)
+	for index := range trades {
+		trades[index] = domain.Trade{Time: january.Add(time.Duration(index) * time.Minute), Price: 40000, Volume: 0.01, Side: domain.Buy}
+	}
+	server.Pairs["XXBTZEUR"].Trades = trades
+
+	count, err := downloader.DownloadTrades("XXBTZEUR", january.Add(99*time.Minute))
+	if err != nil || count != 2400 {
+		t.Fatalf("%d trades were downloaded (%v)", count, err)
+	}
+
+	server.Pairs["XXBTZEUR"].Trades = append(trades, domain.Trade{Time: january.AddDate(0, 0, 3), Price: 41000, Volume: 0.5, Side: domain.Sell})
+
+	count, err = downloader.DownloadTrades("XXBTZEUR", time.Time{})
+	if err != nil || count != 1 {
+		t.Fatalf("%d trades were downloaded when resuming (%v)", count, err)
+	}
+
+	stored, err := store.Trades("XXBTZEUR", time.Time{}, time.Time{})
+	if err != nil || len(stored) != 2401 || !stored[0].Time.Equal(january.Add(100*time.Minute)) || stored[2400].Side != domain.Sell {
+		t.Errorf("%d trades were stored (%v)", len(stored), err)
+	}
+}
+
+func TestDownloadFail(t *testing.T) {
+	server, _, downloader := setupDownloader(t)
+	server.Fail("OHLC", "EService:Unavailable")
+	server.Fail("Trades", "EService:Unavailable")
+
+	_, err := downloader.DownloadCandles("XXBTZEUR", 1440)
+	if err == nil || !strings.HasPrefix(err.Error(), "the XXBTZEUR candles cannot be downloaded :") {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	_, err = downloader.DownloadTrades("XXBTZEUR", time.Time{})
+	if err == nil || !strings.HasPrefix(err.Error(), "the XXBTZEUR trades cannot be downloaded :") || errors.Unwrap(err) == nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
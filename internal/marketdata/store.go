@@ -0,0 +1,296 @@
+// Package marketdata downloads the market history of the traded pairs and stores it locally, for the backtests and the
+// strategies needing a longer price history than the exchanges serve
+package marketdata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"kraken-dca-bot/internal/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store persists the market history of the pairs
+type Store interface {
+	// Candles Get the stored candles of the pair started between `from` and `to` included, oldest first, a zero bound
+	// being unbounded
+	Candles(pair string, interval int, from time.Time, to time.Time) ([]domain.Candle, error)
+	// SaveCandles Store the candles, replacing the stored ones started at the same time
+	SaveCandles(pair string, interval int, candles []domain.Candle) error
+	// LastCandle Get the start time of the latest stored candle, false if none is stored
+	LastCandle(pair string, interval int) (time.Time, bool, error)
+	// Trades Get the stored trades of the pair executed between `from` and `to` included, oldest first, a zero bound
+	// being unbounded
+	Trades(pair string, from time.Time, to time.Time) ([]domain.Trade, error)
+	// SaveTrades Append the trades, then store the cursor of the following ones
+	SaveTrades(pair string, trades []domain.Trade, cursor string) error
+	// TradesCursor Get the cursor of the trades following the stored ones, empty if none is stored
+	TradesCursor(pair string) (string, error)
+}
+
+// partitionLayout names the monthly partition files
+const partitionLayout = "2006-01"
+
+// FileStore is a Store partitioned in a CSV file per pair, data kind and month :
+// `<path>/<pair>/ohlc-<interval>/<month>.csv` and `<path>/<pair>/trades/<month>.csv`
+type FileStore struct {
+	path  string
+	mutex *sync.Mutex
+}
+
+func NewFileStore(path string) Store {
+	return FileStore{
+		path:  path,
+		mutex: &sync.Mutex{},
+	}
+}
+
+func (s FileStore) candlesDirectory(pair string, interval int) string {
+	return filepath.Join(s.path, pair, "ohlc-"+strconv.Itoa(interval))
+}
+
+func (s FileStore) tradesDirectory(pair string) string {
+	return filepath.Join(s.path, pair, "trades")
+}
+
+// partition Get the file of the month of the given time
+func partition(directory string, moment time.Time) string {
+	return filepath.Join(directory, moment.UTC().Format(partitionLayout)+".csv")
+}
+
+// partitions Get the partition files of the directory overlapping the bounds, oldest first
+func partitions(directory string, from time.Time, to time.Time) ([]string, error) {
+	entries, err := ioutil.ReadDir(directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the %s market data directory cannot be read : %w", directory, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		month, err := time.Parse(partitionLayout, strings.TrimSuffix(entry.Name(), ".csv"))
+		if err != nil || entry.IsDir() {
+			continue
+		}
+
+		if !from.IsZero() && month.AddDate(0, 1, 0).Before(from) || !to.IsZero() && month.After(to) {
+			continue
+		}
+		files = append(files, filepath.Join(directory, entry.Name()))
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// within Get whether the time is between the bounds included, a zero bound being unbounded
+func within(moment time.Time, from time.Time, to time.Time) bool {
+	return (from.IsZero() || !moment.Before(from)) && (to.IsZero() || !moment.After(to))
+}
+
+func (s FileStore) Candles(pair string, interval int, from time.Time, to time.Time) ([]domain.Candle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := partitions(s.candlesDirectory(pair, interval), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles []domain.Candle
+	for _, file := range files {
+		stored, err := LoadCsv(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candle := range stored {
+			if within(candle.Time, from, to) {
+				candles = append(candles, candle)
+			}
+		}
+	}
+
+	return candles, nil
+}
+
+func (s FileStore) SaveCandles(pair string, interval int, candles []domain.Candle) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	directory := s.candlesDirectory(pair, interval)
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return fmt.Errorf("the %s market data directory cannot be created : %w", directory, err)
+	}
+
+	months := map[string][]domain.Candle{}
+	for _, candle := range candles {
+		file := partition(directory, candle.Time)
+		months[file] = append(months[file], candle)
+	}
+
+	for file, monthCandles := range months {
+		err = mergeCandles(file, monthCandles)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeCandles Write the candles in the partition file, replacing the stored ones started at the same time
+func mergeCandles(file string, candles []domain.Candle) error {
+	stored, err := LoadCsv(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	merged := map[int64]domain.Candle{}
+	for _, candle := range append(stored, candles...) {
+		merged[candle.Time.Unix()] = candle
+	}
+
+	sorted := make([]domain.Candle, 0, len(merged))
+	for _, candle := range merged {
+		sorted = append(sorted, candle)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	var content bytes.Buffer
+	err = WriteCsv(&content, sorted)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(file, content.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("the %s candles file cannot be written : %w", file, err)
+	}
+
+	return nil
+}
+
+func (s FileStore) LastCandle(pair string, interval int) (time.Time, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := partitions(s.candlesDirectory(pair, interval), time.Time{}, time.Time{})
+	if err != nil || len(files) == 0 {
+		return time.Time{}, false, err
+	}
+
+	candles, err := LoadCsv(files[len(files)-1])
+	if err != nil || len(candles) == 0 {
+		return time.Time{}, false, err
+	}
+
+	return candles[len(candles)-1].Time, true, nil
+}
+
+func (s FileStore) Trades(pair string, from time.Time, to time.Time) ([]domain.Trade, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := partitions(s.tradesDirectory(pair), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []domain.Trade
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("the %s trades file cannot be read : %w", file, err)
+		}
+
+		stored, err := readTrades(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("the %s trades file cannot be parsed : %w", file, err)
+		}
+
+		for _, trade := range stored {
+			if within(trade.Time, from, to) {
+				trades = append(trades, trade)
+			}
+		}
+	}
+
+	return trades, nil
+}
+
+// SaveTrades Append the trades to their partition files. The cursor being saved last, trades appended by an
+// interrupted save are downloaded again.
+func (s FileStore) SaveTrades(pair string, trades []domain.Trade, cursor string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	directory := s.tradesDirectory(pair)
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return fmt.Errorf("the %s market data directory cannot be created : %w", directory, err)
+	}
+
+	for start := 0; start < len(trades); {
+		file := partition(directory, trades[start].Time)
+		end := start + 1
+		for end < len(trades) && partition(directory, trades[end].Time) == file {
+			end++
+		}
+
+		err = appendTrades(file, trades[start:end])
+		if err != nil {
+			return err
+		}
+		start = end
+	}
+
+	err = ioutil.WriteFile(filepath.Join(directory, "cursor"), []byte(cursor), 0600)
+	if err != nil {
+		return fmt.Errorf("the %s trades cursor cannot be written : %w", pair, err)
+	}
+
+	return nil
+}
+
+func appendTrades(file string, trades []domain.Trade) error {
+	output, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("the %s trades file cannot be opened : %w", file, err)
+	}
+	defer output.Close()
+
+	err = writeTrades(output, trades)
+	if err != nil {
+		return err
+	}
+
+	return output.Close()
+}
+
+func (s FileStore) TradesCursor(pair string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cursor, err := ioutil.ReadFile(filepath.Join(s.tradesDirectory(pair), "cursor"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("the %s trades cursor cannot be read : %w", pair, err)
+	}
+
+	return string(cursor), nil
+}
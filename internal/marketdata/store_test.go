@@ -0,0 +1,103 @@
+package marketdata
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var january = time.Date(2022, 1, 30, 0, 0, 0, 0, time.UTC)
+
+// dailyCandles Get `count` daily candles from the given day, closing at increasing prices
+func dailyCandles(from time.Time, count int) []domain.Candle {
+	candles := make([]domain.Candle, count)
+	for index := range candles {
+		price := float64(100 + index)
+		candles[index] = domain.Candle{Time: from.AddDate(0, 0, index), Open: price, High: price, Low: price, Close: price, Volume: 1}
+	}
+
+	return candles
+}
+
+func TestFileStoreCandles(t *testing.T) {
+	path := t.TempDir()
+	store := NewFileStore(path)
+
+	_, found, err := store.LastCandle("XXBTZEUR", 1440)
+	if err != nil || found {
+		t.Errorf("A candle was found in the empty store (%v)", err)
+	}
+
+	err = store.SaveCandles("XXBTZEUR", 1440, dailyCandles(january, 4))
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	// The last candle was running and is replaced
+	update := dailyCandles(january.AddDate(0, 0, 3), 2)
+	update[0].Close = 110
+	err = store.SaveCandles("XXBTZEUR", 1440, update)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	for _, month := range []string{"2022-01.csv", "2022-02.csv"} {
+		if _, err := os.Stat(filepath.Join(path, "XXBTZEUR", "ohlc-1440", month)); err != nil {
+			t.Errorf("The %s partition is missing : %v", month, err)
+		}
+	}
+
+	candles, err := store.Candles("XXBTZEUR", 1440, time.Time{}, time.Time{})
+	if err != nil || len(candles) != 5 || candles[3].Close != 110 || candles[4].Close != 101 {
+		t.Errorf("The stored candles are %v (%v)", candles, err)
+	}
+
+	candles, err = store.Candles("XXBTZEUR", 1440, january.AddDate(0, 0, 1), january.AddDate(0, 0, 2))
+	if err != nil || len(candles) != 2 || !candles[0].Time.Equal(january.AddDate(0, 0, 1)) {
+		t.Errorf("The bounded candles are %v (%v)", candles, err)
+	}
+
+	last, found, err := store.LastCandle("XXBTZEUR", 1440)
+	if err != nil || !found || !last.Equal(january.AddDate(0, 0, 4)) {
+		t.Errorf("The last candle started at %s (%v)", last, err)
+	}
+}
+
+func TestFileStoreTrades(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	cursor, err := store.TradesCursor("XXBTZEUR")
+	if err != nil || cursor != "" {
+		t.Errorf("The empty store cursor is %s (%v)", cursor, err)
+	}
+
+	trades := []domain.Trade{
+		{Time: january.Add(time.Hour), Price: 40000, Volume: 0.1, Side: domain.Buy},
+		{Time: january.AddDate(0, 0, 5), Price: 41000.5, Volume: 0.25, Side: domain.Sell},
+	}
+	err = store.SaveTrades("XXBTZEUR", trades[:1], "1")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+	err = store.SaveTrades("XXBTZEUR", trades[1:], "2")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	stored, err := store.Trades("XXBTZEUR", time.Time{}, time.Time{})
+	if err != nil || len(stored) != 2 || !stored[1].Time.Equal(trades[1].Time) || stored[1].Price != 41000.5 || stored[1].Side != domain.Sell {
+		t.Errorf("The stored trades are %v (%v)", stored, err)
+	}
+
+	stored, err = store.Trades("XXBTZEUR", january.AddDate(0, 0, 1), time.Time{})
+	if err != nil || len(stored) != 1 {
+		t.Errorf("The bounded trades are %v (%v)", stored, err)
+	}
+
+	cursor, err = store.TradesCursor("XXBTZEUR")
+	if err != nil || cursor != "2" {
+		t.Errorf("The cursor is %s (%v)", cursor, err)
+	}
+}
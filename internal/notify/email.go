@@ -39,15 +39,47 @@ func NewEmailNotifier(config *domain.Config) Notifier {
 }
 
 func (en EmailNotifier) NotifyFailure(transaction *domain.Transaction) error {
-	t, err := template.ParseFS(assets.EmailFS, "email/transaction_failed.html")
+	return en.send("Transaction failure", "transaction_failed.html", transaction)
+}
+
+// NotifySummary Send the summary of an investment round, goal progress included
+func (en EmailNotifier) NotifySummary(transactions []*domain.Transaction) error {
+	return en.send("Transaction summary", "transaction_summary.html", struct {
+		Transactions []*domain.Transaction
+	}{transactions})
+}
+
+// NotifyTakeProfit Send the details of an executed take-profit rung
+func (en EmailNotifier) NotifyTakeProfit(transaction *domain.Transaction) error {
+	return en.send("Take-profit executed", "transaction_take_profit.html", transaction)
+}
+
+// NotifyWithdrawal Send the details of a withdrawal attempt, dry-run ones included
+func (en EmailNotifier) NotifyWithdrawal(withdrawal *domain.Withdrawal) error {
+	return en.send("Withdrawal", "withdrawal.html", withdrawal)
+}
+
+// NotifyComparison Send the performance of the live strategy and of the shadow ones
+func (en EmailNotifier) NotifyComparison(comparison *domain.Comparison) error {
+	return en.send("Shadow comparison", "shadow_comparison.html", comparison)
+}
+
+// NotifyDeferral Send the restrictions deferring an investment round and the date of their next check
+func (en EmailNotifier) NotifyDeferral(deferral *domain.Deferral) error {
+	return en.send("Round deferred", "round_deferred.html", deferral)
+}
+
+// send Send an email with the given subject, filling the email template file with `data`
+func (en EmailNotifier) send(subject string, templateFile string, data interface{}) error {
+	t, err := template.ParseFS(assets.EmailFS, "email/"+templateFile)
 	if err != nil {
-		return fmt.Errorf("failed to parse the transaction failure template file : %w", err)
+		return fmt.Errorf("failed to parse the %s template file : %w", templateFile, err)
 	}
 
 	var buffer bytes.Buffer
-	err = t.Execute(&buffer, transaction)
+	err = t.Execute(&buffer, data)
 	if err != nil {
-		return fmt.Errorf("failed to fill the transaction failure template file : %w", err)
+		return fmt.Errorf("failed to fill the %s template file : %w", templateFile, err)
 	}
 
 	smtpClient, err := en.client.Connect()
@@ -58,7 +90,7 @@ func (en EmailNotifier) NotifyFailure(transaction *domain.Transaction) error {
 	email := newEmail()
 	email.SetFrom("Kraken DCA Bot <" + en.config.Smtp.From + ">").
 		AddTo(en.config.Notify).
-		SetSubject("Kraken DCA Bot - Transaction failure")
+		SetSubject("Kraken DCA Bot - " + subject)
 	email.SetBody(mail.TextHTML, buffer.String())
 
 	if email.Error != nil {
@@ -4,4 +4,9 @@ import "kraken-dca-bot/internal/domain"
 
 type Notifier interface {
 	NotifyFailure(transaction *domain.Transaction) error
+	NotifySummary(transactions []*domain.Transaction) error
+	NotifyTakeProfit(transaction *domain.Transaction) error
+	NotifyWithdrawal(withdrawal *domain.Withdrawal) error
+	NotifyComparison(comparison *domain.Comparison) error
+	NotifyDeferral(deferral *domain.Deferral) error
 }
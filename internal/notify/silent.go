@@ -0,0 +1,34 @@
+package notify
+
+import "kraken-dca-bot/internal/domain"
+
+// SilentNotifier discards the notifications, for the simulated strategies whose outcome is reported otherwise
+type SilentNotifier struct{}
+
+func NewSilentNotifier() Notifier {
+	return SilentNotifier{}
+}
+
+func (SilentNotifier) NotifyFailure(*domain.Transaction) error {
+	return nil
+}
+
+func (SilentNotifier) NotifySummary([]*domain.Transaction) error {
+	return nil
+}
+
+func (SilentNotifier) NotifyTakeProfit(*domain.Transaction) error {
+	return nil
+}
+
+func (SilentNotifier) NotifyWithdrawal(*domain.Withdrawal) error {
+	return nil
+}
+
+func (SilentNotifier) NotifyComparison(*domain.Comparison) error {
+	return nil
+}
+
+func (SilentNotifier) NotifyDeferral(*domain.Deferral) error {
+	return nil
+}
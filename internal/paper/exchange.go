@@ -0,0 +1,186 @@
+// Package paper simulates the trading on a virtual portfolio, using the market data of a real exchange
+package paper
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/storage"
+	"sync"
+)
+
+// portfolioKey is the state key of the virtual portfolio
+const portfolioKey = "portfolio"
+
+// Portfolio is the virtual account of the paper trading
+type Portfolio struct {
+	Balances map[string]float64
+	Orders   map[string]exchange.Order
+	// OrderCount is the number of orders placed, used to generate their ids
+	OrderCount int
+}
+
+// paperExchange is an exchange filling the orders at the market prices of a real exchange, without placing them
+type paperExchange struct {
+	market exchange.Exchange
+	config domain.Paper
+	state  storage.State
+	mutex  *sync.Mutex
+}
+
+// NewExchange Get a paper exchange simulating the trading on `market`, its portfolio being persisted in `state`
+func NewExchange(market exchange.Exchange, config domain.Paper, state storage.State) exchange.Exchange {
+	return paperExchange{
+		market: market,
+		config: config,
+		state:  state,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// portfolio Load the virtual portfolio, initialized with the configured balances the first time
+func (p paperExchange) portfolio() (Portfolio, error) {
+	var portfolio Portfolio
+	found, err := p.state.Load(portfolioKey, &portfolio)
+	if err != nil {
+		return Portfolio{}, fmt.Errorf("the paper portfolio cannot be loaded : %w", err)
+	}
+
+	if !found {
+		portfolio.Balances = map[string]float64{}
+		for asset, quantity := range p.config.Balances {
+			portfolio.Balances[asset] = quantity
+		}
+	}
+
+	if portfolio.Orders == nil {
+		portfolio.Orders = map[string]exchange.Order{}
+	}
+
+	return portfolio, nil
+}
+
+// Balances Get the virtual portfolio balances
+func (p paperExchange) Balances() (map[string]float64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	portfolio, err := p.portfolio()
+	if err != nil {
+		return nil, err
+	}
+
+	return portfolio.Balances, nil
+}
+
+func (p paperExchange) Ticker(pair string) (exchange.Ticker, error) {
+	return p.market.Ticker(pair)
+}
+
+// Fee Get the configured fee percentage, the exchange one requiring an account
+func (p paperExchange) Fee(string) (float64, error) {
+	return p.config.Fee, nil
+}
+
+func (p paperExchange) Pair(pair string) (exchange.Pair, error) {
+	return p.market.Pair(pair)
+}
+
+func (p paperExchange) Candles(pair string, interval int) ([]domain.Candle, error) {
+	return p.market.Candles(pair, interval)
+}
+
+// PlaceOrder Fill the whole order at the current ask or bid price, the fee being charged in quote currency like on
+// Kraken, and update the virtual portfolio
+func (p paperExchange) PlaceOrder(order exchange.Order) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	rules, err := p.market.Pair(order.Pair)
+	if err != nil {
+		return "", err
+	}
+
+	if order.Volume < rules.MinVolume {
+		return "", fmt.Errorf("the %f %s volume is below the %f minimum", order.Volume, order.Pair, rules.MinVolume)
+	}
+
+	ticker, err := p.market.Ticker(order.Pair)
+	if err != nil {
+		return "", err
+	}
+
+	portfolio, err := p.portfolio()
+	if err != nil {
+		return "", err
+	}
+
+	order.Price = ticker.Ask
+	if order.Side == exchange.Sell {
+		order.Price = ticker.Bid
+	}
+	cost := order.Volume * order.Price
+	fee := cost * p.config.Fee / 100
+
+	switch order.Side {
+	case exchange.Buy:
+		if portfolio.Balances[rules.Quote] < cost+fee {
+			return "", fmt.Errorf("insufficient funds : %.2f %s held, %.2f required", portfolio.Balances[rules.Quote], rules.Quote, cost+fee)
+		}
+		portfolio.Balances[rules.Quote] -= cost + fee
+		portfolio.Balances[rules.Base] += order.Volume
+	case exchange.Sell:
+		if portfolio.Balances[rules.Base] < order.Volume {
+			return "", fmt.Errorf("insufficient funds : %f %s held, %f required", portfolio.Balances[rules.Base], rules.Base, order.Volume)
+		}
+		portfolio.Balances[rules.Base] -= order.Volume
+		portfolio.Balances[rules.Quote] += cost - fee
+	default:
+		return "", fmt.Errorf("the %s order side is unknown", order.Side)
+	}
+
+	if order.Validate {
+		return "", nil
+	}
+
+	portfolio.OrderCount++
+	order.Id = fmt.Sprintf("PAPER-%05d", portfolio.OrderCount)
+	order.Status = exchange.Closed
+	order.Filled = order.Volume
+	order.Fee = fee / order.Price
+	portfolio.Orders[order.Id] = order
+
+	err = p.state.Save(portfolioKey, portfolio)
+	if err != nil {
+		return "", fmt.Errorf("the paper portfolio cannot be saved : %w", err)
+	}
+
+	return order.Id, nil
+}
+
+func (p paperExchange) QueryOrder(id string) (exchange.Order, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	portfolio, err := p.portfolio()
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	order, ok := portfolio.Orders[id]
+	if !ok {
+		return exchange.Order{}, fmt.Errorf("the %s order is unknown", id)
+	}
+
+	return order, nil
+}
+
+// CancelOrder Always fail, paper orders being filled as soon as they are placed
+func (p paperExchange) CancelOrder(id string) error {
+	order, err := p.QueryOrder(id)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("the %s order is %s and cannot be canceled", id, order.Status)
+}
@@ -0,0 +1,114 @@
+package paper
+
+import (
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/mocks"
+	"kraken-dca-bot/internal/storage"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+var btcEur = exchange.Pair{Base: "XXBT", Quote: "ZEUR", MinVolume: 0.0001}
+
+func TestPlaceOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	market := mocks.NewMockExchange(controller)
+	market.EXPECT().Pair("XXBTZEUR").Return(btcEur, nil).AnyTimes()
+	market.EXPECT().Ticker("XXBTZEUR").Return(exchange.Ticker{Ask: 20000, Bid: 19000}, nil).AnyTimes()
+
+	path := filepath.Join(t.TempDir(), "paper.json")
+	config := domain.Paper{Fee: 0.5, Balances: map[string]float64{"ZEUR": 1000}}
+	paperExchange := NewExchange(market, config, storage.NewFileState(path))
+
+	id, err := paperExchange.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 0.01})
+	if err != nil || id != "PAPER-00001" {
+		t.Fatalf("The order %s was not placed : %v", id, err)
+	}
+
+	id, err = paperExchange.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Sell, Volume: 0.005})
+	if err != nil || id != "PAPER-00002" {
+		t.Fatalf("The order %s was not placed : %v", id, err)
+	}
+
+	// The portfolio is reloaded from the file by a new instance
+	paperExchange = NewExchange(market, config, storage.NewFileState(path))
+
+	balances, err := paperExchange.Balances()
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+	if math.Abs(balances["ZEUR"]-(1000-201+94.525)) > 1e-9 || math.Abs(balances["XXBT"]-0.005) > 1e-9 {
+		t.Errorf("The balances are %v", balances)
+	}
+
+	order, err := paperExchange.QueryOrder("PAPER-00001")
+	if err != nil || order.Status != exchange.Closed || order.Price != 20000 || order.Filled != 0.01 {
+		t.Errorf("The queried order is %+v (%v)", order, err)
+	}
+
+	if paperExchange.CancelOrder("PAPER-00001") == nil {
+		t.Errorf("A filled order was canceled")
+	}
+}
+
+func TestPlaceOrderFail(t *testing.T) {
+	cases := []struct {
+		name  string
+		order exchange.Order
+	}{
+		{"insufficient quote", exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 1}},
+		{"insufficient base", exchange.Order{Pair: "XXBTZEUR", Side: exchange.Sell, Volume: 1}},
+		{"below minimum", exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 0.00001}},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	market := mocks.NewMockExchange(controller)
+	market.EXPECT().Pair("XXBTZEUR").Return(btcEur, nil).AnyTimes()
+	market.EXPECT().Ticker("XXBTZEUR").Return(exchange.Ticker{Ask: 20000, Bid: 19000}, nil).AnyTimes()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := domain.Paper{Fee: 0.26, Balances: map[string]float64{"ZEUR": 100}}
+			paperExchange := NewExchange(market, config, storage.NewFileState(filepath.Join(t.TempDir(), "paper.json")))
+
+			_, err := paperExchange.PlaceOrder(c.order)
+			if err == nil {
+				t.Errorf("The order was placed")
+			}
+
+			balances, _ := paperExchange.Balances()
+			if balances["ZEUR"] != 100 || balances["XXBT"] != 0 {
+				t.Errorf("The balances were changed : %v", balances)
+			}
+		})
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	market := mocks.NewMockExchange(controller)
+	market.EXPECT().Pair("XXBTZEUR").Return(btcEur, nil)
+	market.EXPECT().Ticker("XXBTZEUR").Return(exchange.Ticker{Ask: 20000, Bid: 19000}, nil)
+
+	config := domain.Paper{Fee: 0.26, Balances: map[string]float64{"ZEUR": 1000}}
+	paperExchange := NewExchange(market, config, storage.NewFileState(filepath.Join(t.TempDir(), "paper.json")))
+
+	id, err := paperExchange.PlaceOrder(exchange.Order{Pair: "XXBTZEUR", Side: exchange.Buy, Volume: 0.01, Validate: true})
+	if err != nil || id != "" {
+		t.Errorf("The validation returned %s (%v)", id, err)
+	}
+
+	balances, _ := paperExchange.Balances()
+	if balances["ZEUR"] != 1000 {
+		t.Errorf("A validated order changed the balances : %v", balances)
+	}
+}
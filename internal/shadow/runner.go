@@ -0,0 +1,190 @@
+// Package shadow runs strategies on paper portfolios next to the live one and compares their performance
+package shadow
+
+//go:generate mockgen -destination=../mocks/mock_shadow_runner.go -package=mocks . Runner
+
+import (
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/exchange"
+	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/notify"
+	"kraken-dca-bot/internal/paper"
+	"kraken-dca-bot/internal/storage"
+	"log"
+	"time"
+)
+
+// State keys of the shadows start, from which the strategies are compared, and of the latest comparison report
+const (
+	sinceKey  = "shadows/since"
+	reportKey = "shadows/report"
+)
+
+// Runner runs the shadow strategies after each live round
+type Runner interface {
+	// Run Run an investment round of every shadow strategy, then report the comparison when it's due
+	Run()
+}
+
+// strategy is a shadow strategy trading a paper portfolio
+type strategy struct {
+	name     string
+	investor kraken.Investor
+	history  storage.History
+}
+
+type runner struct {
+	config     domain.Shadows
+	strategies []strategy
+	market     exchange.Exchange
+	history    storage.History
+	notifier   notify.Notifier
+	state      storage.State
+	// staging only validates the shadow orders and leaves the shadows start and reports unsaved
+	staging bool
+	now     func() time.Time
+}
+
+// NewRunner Get a runner of the configured shadow strategies, trading at the `market` prices and dating their rounds
+// with the `now` clock of the live strategy. The live strategy is compared through its `history`, the shadows start and
+// reports being remembered in the live `state`. Nothing is saved by `staging` runs.
+func NewRunner(config domain.Config, market exchange.Exchange, history storage.History, notifier notify.Notifier, state storage.State, staging bool, now func() time.Time) Runner {
+	r := runner{
+		config:   *config.Shadows,
+		market:   market,
+		history:  history,
+		notifier: notifier,
+		state:    state,
+		staging:  staging,
+		now:      now,
+	}
+
+	for _, shadow := range config.Shadows.Strategies {
+		shadowConfig := shadow.Config(config)
+		account := paper.NewExchange(market, shadow.Paper, storage.NewFileState(shadow.Paper.Portfolio))
+		shadowHistory := storage.NewFileHistory(shadow.Storage)
+
+		r.strategies = append(r.strategies, strategy{
+			name:     shadow.Name,
+			investor: kraken.NewInvestingServiceWithClock(shadowConfig, kraken.NewAccount(account), kraken.NewTrader(account, staging), notify.NewSilentNotifier(), shadowHistory, storage.NewFileState(shadow.State), now),
+			history:  shadowHistory,
+		})
+	}
+
+	return r
+}
+
+func (r runner) Run() {
+	for _, strategy := range r.strategies {
+		log.Printf("Running the %s shadow strategy...", strategy.name)
+
+		for _, transaction := range strategy.investor.Invest() {
+			if transaction.Exception != nil {
+				log.Printf("[%s] The shadow transaction failed : %v", strategy.name, transaction.Exception)
+			}
+		}
+	}
+
+	err := r.report()
+	if err != nil {
+		log.Printf("The shadow comparison could not be reported : %v", err)
+	}
+}
+
+// report Notify the comparison of the strategies when the report interval elapsed since the previous one, or since
+// the shadows start
+func (r runner) report() error {
+	now := r.now()
+
+	var since time.Time
+	found, err := r.state.Load(sinceKey, &since)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if r.staging {
+			return nil
+		}
+
+		return r.state.Save(sinceKey, now)
+	}
+
+	interval, err := r.config.ReportInterval()
+	if err != nil {
+		return err
+	}
+
+	last := since
+	_, err = r.state.Load(reportKey, &last)
+	if err != nil {
+		return err
+	}
+	if now.Sub(last) < interval {
+		return nil
+	}
+
+	comparison, err := r.compare(since)
+	if err != nil {
+		return err
+	}
+
+	err = r.notifier.NotifyComparison(comparison)
+	if err != nil {
+		return fmt.Errorf("failed to notify the shadow comparison : %w", err)
+	}
+
+	if r.staging {
+		return nil
+	}
+
+	return r.state.Save(reportKey, now)
+}
+
+// compare Get the performance of the live transactions since the shadows started and of the shadow ones, the volumes
+// held being valued at the current bid prices
+func (r runner) compare(since time.Time) (*domain.Comparison, error) {
+	live, err := r.history.Transactions()
+	if err != nil {
+		return nil, fmt.Errorf("the live history cannot be read : %w", err)
+	}
+
+	var recent []domain.Transaction
+	for _, transaction := range live {
+		if !transaction.Date.Before(since) {
+			recent = append(recent, transaction)
+		}
+	}
+
+	histories := [][]domain.Transaction{recent}
+	for _, strategy := range r.strategies {
+		transactions, err := strategy.history.Transactions()
+		if err != nil {
+			return nil, fmt.Errorf("the %s shadow history cannot be read : %w", strategy.name, err)
+		}
+		histories = append(histories, transactions)
+	}
+
+	prices := map[string]float64{}
+	for _, transactions := range histories {
+		for _, transaction := range transactions {
+			if _, ok := prices[transaction.Pair]; ok {
+				continue
+			}
+
+			ticker, err := r.market.Ticker(transaction.Pair)
+			if err != nil {
+				return nil, fmt.Errorf("the %s price cannot be collected : %w", transaction.Pair, err)
+			}
+			prices[transaction.Pair] = ticker.Bid
+		}
+	}
+
+	comparison := &domain.Comparison{Since: since}
+	comparison.Performances = append(comparison.Performances, domain.NewPerformance("live", histories[0], prices))
+	for index, strategy := range r.strategies {
+		comparison.Performances = append(comparison.Performances, domain.NewPerformance(strategy.name, histories[index+1], prices))
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,131 @@
+package shadow
+
+import (
+	"github.com/golang/mock/gomock"
+	"kraken-dca-bot/internal/domain"
+	"kraken-dca-bot/internal/fake"
+	"kraken-dca-bot/internal/kraken"
+	"kraken-dca-bot/internal/mocks"
+	"kraken-dca-bot/internal/storage"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := fake.NewServer()
+	defer server.Close()
+	server.Pairs["XXBTZEUR"] = &fake.Pair{Base: "XXBT", Quote: "ZEUR", Ask: 20000, Bid: 20000, Fee: 0.26, LotDecimals: 8}
+	market := kraken.NewExchange(kraken.NewApiWithClient("", "", server.Client()))
+
+	directory := t.TempDir()
+	config := domain.Config{
+		Frequency: "24h",
+		Currency:  "ZEUR",
+		Pairs:     []domain.DCAPair{{Pair: "XXBTZEUR", Amount: 10}},
+		Shadows: &domain.Shadows{
+			Report: "7d",
+			Strategies: []domain.Shadow{{
+				Name:    "double",
+				Paper:   domain.Paper{Portfolio: filepath.Join(directory, "portfolio.json"), Fee: 0.26, Balances: map[string]float64{"ZEUR": 1000}},
+				Storage: filepath.Join(directory, "history.json"),
+				State:   filepath.Join(directory, "state.json"),
+				Pairs:   []domain.DCAPair{{Pair: "XXBTZEUR", Amount: 20}},
+			}},
+		},
+	}
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := storage.NewMemoryHistory()
+	notifier := mocks.NewMockNotifier(controller)
+	r := NewRunner(config, market, history, notifier, storage.NewMemoryState(), false, func() time.Time {
+		return now
+	})
+
+	// The shadows start with the first round, without any report
+	r.Run()
+
+	err := history.Record(domain.NewTransaction("XXBTZEUR").Complete("LIVE1", 20000, 0.0005, 0.0000013))
+	if err != nil {
+		t.Fatalf("An unexpected error occurred : %v", err)
+	}
+
+	now = now.AddDate(0, 0, 3)
+	r.Run()
+
+	var comparison *domain.Comparison
+	notifier.EXPECT().NotifyComparison(gomock.Any()).Do(func(c *domain.Comparison) {
+		comparison = c
+	})
+
+	server.SetPrices("XXBTZEUR", 22000, 22000)
+	now = now.AddDate(0, 0, 4)
+	r.Run()
+
+	if comparison == nil || len(comparison.Performances) != 2 {
+		t.Fatalf("The comparison is %+v", comparison)
+	}
+
+	live, double := comparison.Performances[0], comparison.Performances[1]
+	if live.Name != "live" || math.Abs(live.Invested-10.026) > 1e-6 || math.Abs(live.Value-11) > 1e-6 {
+		t.Errorf("The live performance is %+v", live)
+	}
+
+	if double.Name != "double" || math.Abs(double.Invested-60) > 1e-6 || math.Abs(double.Value-(2*20.0/20000+20.0/22000)*(1-0.0026)*22000) > 1e-6 {
+		t.Errorf("The shadow performance is %+v", double)
+	}
+
+	// The next report is due a week later
+	now = now.AddDate(0, 0, 1)
+	r.Run()
+}
+
+func TestRunStaging(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := fake.NewServer()
+	defer server.Close()
+	server.Pairs["XXBTZEUR"] = &fake.Pair{Base: "XXBT", Quote: "ZEUR", Ask: 20000, Bid: 20000, Fee: 0.26, LotDecimals: 8}
+	market := kraken.NewExchange(kraken.NewApiWithClient("", "", server.Client()))
+
+	directory := t.TempDir()
+	shadow := domain.Shadow{
+		Name:    "double",
+		Paper:   domain.Paper{Portfolio: filepath.Join(directory, "portfolio.json"), Fee: 0.26, Balances: map[string]float64{"ZEUR": 1000}},
+		Storage: filepath.Join(directory, "history.json"),
+		State:   filepath.Join(directory, "state.json"),
+		Pairs:   []domain.DCAPair{{Pair: "XXBTZEUR", Amount: 20}},
+	}
+	config := domain.Config{
+		Frequency: "24h",
+		Currency:  "ZEUR",
+		Pairs:     []domain.DCAPair{{Pair: "XXBTZEUR", Amount: 10}},
+		Shadows:   &domain.Shadows{Report: "7d", Strategies: []domain.Shadow{shadow}},
+	}
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	state := storage.NewMemoryState()
+	r := NewRunner(config, market, storage.NewMemoryHistory(), mocks.NewMockNotifier(controller), state, true, func() time.Time {
+		return now
+	}).(runner)
+
+	r.Run()
+
+	for _, file := range []string{shadow.Paper.Portfolio, shadow.Storage, shadow.State} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("The staging run wrote %s (%v)", file, err)
+		}
+	}
+
+	var since time.Time
+	found, err := state.Load(sinceKey, &since)
+	if err != nil || found {
+		t.Errorf("The staging run saved the shadows start %v (%v)", since, err)
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+//go:generate mockgen -destination=../mocks/mock_history.go -package=mocks . History
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"kraken-dca-bot/internal/domain"
+	"os"
+	"sync"
+)
+
+// History persists the transactions executed by the bot
+type History interface {
+	Record(transaction *domain.Transaction) error
+	Transactions() ([]domain.Transaction, error)
+}
+
+// FileHistory is a History stored as a JSON file
+type FileHistory struct {
+	path  string
+	mutex *sync.Mutex
+}
+
+func NewFileHistory(path string) History {
+	return FileHistory{
+		path:  path,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Record Append the transaction to the history file
+func (h FileHistory) Record(transaction *domain.Transaction) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	transactions, err := h.read()
+	if err != nil {
+		return err
+	}
+
+	transactions = append(transactions, *transaction)
+	content, err := json.MarshalIndent(transactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize the transaction history : %w", err)
+	}
+
+	err = ioutil.WriteFile(h.path, content, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot write the transaction history file : %w", err)
+	}
+
+	return nil
+}
+
+// Transactions Get all the recorded transactions, oldest first
+func (h FileHistory) Transactions() ([]domain.Transaction, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.read()
+}
+
+func (h FileHistory) read() ([]domain.Transaction, error) {
+	content, err := ioutil.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []domain.Transaction{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the transaction history file : %w", err)
+	}
+
+	var transactions []domain.Transaction
+	err = json.Unmarshal(content, &transactions)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the transaction history file : %w", err)
+	}
+
+	return transactions, nil
+}
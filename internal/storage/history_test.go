@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"io/ioutil"
+	"kraken-dca-bot/internal/domain"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileHistory(t *testing.T) {
+	history := NewFileHistory(filepath.Join(t.TempDir(), "history.json"))
+
+	transactions, err := history.Transactions()
+	if err != nil || len(transactions) != 0 {
+		t.Errorf("The empty history returned %v (%v)", transactions, err)
+	}
+
+	err = history.Record(domain.NewTransaction("XETHZEUR").Complete("TXID1", 1500, 0.01, 0.0001))
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+	err = history.Record(domain.NewTransaction("XXBTZEUR").Complete("TXID2", 20000, 0.001, 0.00001))
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	transactions, err = history.Transactions()
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	if len(transactions) != 2 || transactions[0].Id != "TXID1" || transactions[1].Pair != "XXBTZEUR" || transactions[1].MarketPrice != 20000 {
+		t.Errorf("The history transactions are %v", transactions)
+	}
+}
+
+func TestFileHistoryParseFail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	err := ioutil.WriteFile(path, []byte("not json"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewFileHistory(path).Transactions()
+	if err == nil || !strings.HasPrefix(err.Error(), "cannot parse the transaction history file :") {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+}
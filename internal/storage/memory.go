@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"kraken-dca-bot/internal/domain"
+	"sync"
+)
+
+// MemoryState is a State kept in memory, for the simulations which must not alter the bot state file
+type MemoryState struct {
+	values map[string]json.RawMessage
+	mutex  *sync.Mutex
+}
+
+func NewMemoryState() State {
+	return MemoryState{
+		values: map[string]json.RawMessage{},
+		mutex:  &sync.Mutex{},
+	}
+}
+
+func (s MemoryState) Load(key string, value interface{}) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	raw, ok := s.values[key]
+	if !ok {
+		return false, nil
+	}
+
+	err := json.Unmarshal(raw, value)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse the %s state : %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (s MemoryState) Save(key string, value interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cannot serialize the %s state : %w", key, err)
+	}
+	s.values[key] = raw
+
+	return nil
+}
+
+// MemoryHistory is a History kept in memory, for the simulations which must not alter the bot history file
+type MemoryHistory struct {
+	transactions *[]domain.Transaction
+	mutex        *sync.Mutex
+}
+
+func NewMemoryHistory() History {
+	return MemoryHistory{
+		transactions: &[]domain.Transaction{},
+		mutex:        &sync.Mutex{},
+	}
+}
+
+// Record Append the transaction to the history
+func (h MemoryHistory) Record(transaction *domain.Transaction) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	*h.transactions = append(*h.transactions, *transaction)
+
+	return nil
+}
+
+// Transactions Get a copy of the recorded transactions, oldest first
+func (h MemoryHistory) Transactions() ([]domain.Transaction, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return append([]domain.Transaction{}, *h.transactions...), nil
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"testing"
+)
+
+func TestMemoryState(t *testing.T) {
+	state := NewMemoryState()
+
+	var value float64
+	found, err := state.Load("rollover/XXBTZEUR", &value)
+	if err != nil || found {
+		t.Errorf("An unsaved value was found : %v (%v)", value, err)
+	}
+
+	err = state.Save("rollover/XXBTZEUR", 12.5)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	found, err = state.Load("rollover/XXBTZEUR", &value)
+	if err != nil || !found || value != 12.5 {
+		t.Errorf("The loaded value is %v (%v)", value, err)
+	}
+}
+
+func TestMemoryHistory(t *testing.T) {
+	history := NewMemoryHistory()
+
+	err := history.Record(domain.NewTransaction("XETHZEUR").Complete("TXID1", 1500, 0.01, 0.0001))
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	transactions, err := history.Transactions()
+	if err != nil || len(transactions) != 1 || transactions[0].Id != "TXID1" {
+		t.Errorf("The history transactions are %v (%v)", transactions, err)
+	}
+
+	transactions[0].Id = "CHANGED"
+	transactions, _ = history.Transactions()
+	if transactions[0].Id != "TXID1" {
+		t.Errorf("The recorded transactions were modified through a copy : %v", transactions)
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+//go:generate mockgen -destination=../mocks/mock_state.go -package=mocks . State
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// State persists the values the bot needs to remember between rounds and restarts
+type State interface {
+	// Load Unmarshal the value saved at `key` into `value`, returning false if nothing was saved
+	Load(key string, value interface{}) (bool, error)
+	Save(key string, value interface{}) error
+}
+
+// FileState is a State stored as a JSON object in a file
+type FileState struct {
+	path  string
+	mutex *sync.Mutex
+}
+
+func NewFileState(path string) State {
+	return FileState{
+		path:  path,
+		mutex: &sync.Mutex{},
+	}
+}
+
+func (s FileState) Load(key string, value interface{}) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	raw, ok := values[key]
+	if !ok {
+		return false, nil
+	}
+
+	err = json.Unmarshal(raw, value)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse the %s state : %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (s FileState) Save(key string, value interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cannot serialize the %s state : %w", key, err)
+	}
+	values[key] = raw
+
+	content, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize the state : %w", err)
+	}
+
+	err = ioutil.WriteFile(s.path, content, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot write the state file : %w", err)
+	}
+
+	return nil
+}
+
+func (s FileState) read() (map[string]json.RawMessage, error) {
+	content, err := ioutil.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]json.RawMessage{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the state file : %w", err)
+	}
+
+	values := map[string]json.RawMessage{}
+	err = json.Unmarshal(content, &values)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the state file : %w", err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileState(t *testing.T) {
+	state := NewFileState(filepath.Join(t.TempDir(), "state.json"))
+
+	var value float64
+	found, err := state.Load("rollover/XXBTZEUR", &value)
+	if err != nil || found {
+		t.Errorf("An unsaved value was found : %v (%v)", value, err)
+	}
+
+	err = state.Save("rollover/XXBTZEUR", 12.5)
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+	err = state.Save("ledger", "L4UESK-KG3EQ-UFO4T5")
+	if err != nil {
+		t.Errorf("An unexpected error occurred : %v", err)
+	}
+
+	found, err = state.Load("rollover/XXBTZEUR", &value)
+	if err != nil || !found || value != 12.5 {
+		t.Errorf("The loaded value is %v (%v)", value, err)
+	}
+
+	var ledger string
+	found, err = state.Load("ledger", &ledger)
+	if err != nil || !found || ledger != "L4UESK-KG3EQ-UFO4T5" {
+		t.Errorf("The loaded value is %v (%v)", ledger, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package webhook receives signed buy requests from external systems (TradingView alerts, scripts...)
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"kraken-dca-bot/internal/domain"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SignatureHeader is the header holding the hex encoded HMAC-SHA256 signature of the request body
+const SignatureHeader = "X-Signature"
+
+// maxAge is the maximum age of a request, older requests being rejected to prevent replays
+const maxAge = 5 * time.Minute
+
+// Request asks the bot to buy `Amount` of `Pair`
+type Request struct {
+	Pair   string  `json:"pair"`
+	Amount float64 `json:"amount"`
+	// Timestamp is the Unix time at which the request was sent
+	Timestamp int64 `json:"timestamp"`
+}
+
+type handler struct {
+	config   domain.Webhook
+	requests chan<- Request
+	now      func() time.Time
+
+	// mutex guards the signatures of the accepted requests, kept until their timestamp leaves the maxAge window
+	mutex      *sync.Mutex
+	signatures map[string]time.Time
+}
+
+// NewHandler Get the HTTP handler validating the webhook requests and sending the valid ones to `requests`
+func NewHandler(config domain.Webhook, requests chan<- Request) http.Handler {
+	return newHandler(config, requests, time.Now)
+}
+
+func newHandler(config domain.Webhook, requests chan<- Request, now func() time.Time) handler {
+	return handler{
+		config:     config,
+		requests:   requests,
+		now:        now,
+		mutex:      &sync.Mutex{},
+		signatures: map[string]time.Time{},
+	}
+}
+
+func (h handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "only POST requests are accepted", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(writer, request.Body, 4096))
+	if err != nil {
+		http.Error(writer, "cannot read the request body", http.StatusBadRequest)
+		return
+	}
+
+	signature := request.Header.Get(SignatureHeader)
+	if !h.verify(body, signature) {
+		log.Printf("A webhook request with an invalid signature was rejected")
+		http.Error(writer, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var webhookRequest Request
+	err = json.Unmarshal(body, &webhookRequest)
+	if err != nil {
+		http.Error(writer, "cannot parse the request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.validate(webhookRequest)
+	if err != nil {
+		log.Printf("A webhook request was rejected : %v", err)
+		http.Error(writer, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if !h.remember(signature, webhookRequest) {
+		log.Printf("A replayed webhook request was rejected")
+		http.Error(writer, "the request was already received", http.StatusConflict)
+		return
+	}
+
+	select {
+	case h.requests <- webhookRequest:
+		log.Printf("Webhook request accepted : %.2f on %s", webhookRequest.Amount, webhookRequest.Pair)
+		writer.WriteHeader(http.StatusAccepted)
+	default:
+		// The request can be sent again once the pending ones are handled
+		h.forget(signature)
+		http.Error(writer, "too many pending requests", http.StatusServiceUnavailable)
+	}
+}
+
+// remember Record the signature of the request, false if it was already received. The signatures are forgotten once
+// their request is too old to be accepted anyway.
+func (h handler) remember(signature string, request Request) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := h.now()
+	for known, expiry := range h.signatures {
+		if now.After(expiry) {
+			delete(h.signatures, known)
+		}
+	}
+
+	if _, ok := h.signatures[signature]; ok {
+		return false
+	}
+	h.signatures[signature] = time.Unix(request.Timestamp, 0).Add(maxAge)
+
+	return true
+}
+
+// forget Remove the signature of a request that wasn't accepted
+func (h handler) forget(signature string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.signatures, signature)
+}
+
+// verify Check the body signature against the configured secret
+func (h handler) verify(body []byte, signature string) bool {
+	return h.config.Secret != "" && hmac.Equal([]byte(Sign(body, h.config.Secret)), []byte(signature))
+}
+
+// validate Check the request is recent and targets an allowed pair within its maximum amount
+func (h handler) validate(request Request) error {
+	age := h.now().Sub(time.Unix(request.Timestamp, 0))
+	if math.Abs(float64(age)) > float64(maxAge) {
+		return fmt.Errorf("the request timestamp is %s away from the current time", age)
+	}
+
+	allowed, ok := h.config.Allowed(request.Pair)
+	if !ok {
+		return fmt.Errorf("the %s pair is not allowed", request.Pair)
+	}
+
+	if request.Amount <= 0 {
+		return fmt.Errorf("the %.2f amount must be positive", request.Amount)
+	}
+
+	if allowed.Max > 0 && request.Amount > allowed.Max {
+		return fmt.Errorf("the %.2f amount exceeds the %.2f %s maximum", request.Amount, allowed.Max, request.Pair)
+	}
+
+	return nil
+}
+
+// Sign Get the hex encoded signature of the body for the given secret
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
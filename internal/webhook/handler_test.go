@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"kraken-dca-bot/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var config = domain.Webhook{
+	Secret: "webhook_secret",
+	Pairs:  []domain.WebhookPair{{Pair: "XXBTZEUR", Max: 50}},
+}
+
+var now = time.Unix(1665400000, 0)
+
+func send(t *testing.T, method string, body string, signature string) (*httptest.ResponseRecorder, []Request) {
+	return sendTo(newHandler(config, make(chan Request, 1), func() time.Time { return now }), method, body, signature)
+}
+
+// sendTo Send the request to the handler and get the response and the requests it accepted
+func sendTo(h handler, method string, body string, signature string) (*httptest.ResponseRecorder, []Request) {
+	requests := make(chan Request, 1)
+	h.requests = requests
+
+	request := httptest.NewRequest(method, "/", strings.NewReader(body))
+	request.Header.Set(SignatureHeader, signature)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+	close(requests)
+
+	var received []Request
+	for r := range requests {
+		received = append(received, r)
+	}
+
+	return recorder, received
+}
+
+func TestHandlerAccepted(t *testing.T) {
+	body := `{"pair": "XXBTZEUR", "amount": 25, "timestamp": 1665400010}`
+
+	recorder, received := send(t, http.MethodPost, body, Sign([]byte(body), "webhook_secret"))
+
+	if recorder.Code != http.StatusAccepted {
+		t.Errorf("The response code is %d : %s", recorder.Code, recorder.Body.String())
+	}
+
+	if len(received) != 1 || received[0] != (Request{Pair: "XXBTZEUR", Amount: 25, Timestamp: 1665400010}) {
+		t.Errorf("The received requests are %v", received)
+	}
+}
+
+func TestHandlerRejected(t *testing.T) {
+	cases := []struct {
+		method string
+		body   string
+		secret string
+		code   int
+	}{
+		{http.MethodGet, `{}`, "webhook_secret", http.StatusMethodNotAllowed},
+		{http.MethodPost, `{"pair": "XXBTZEUR", "amount": 25, "timestamp": 1665400010}`, "wrong_secret", http.StatusUnauthorized},
+		{http.MethodPost, `not json`, "webhook_secret", http.StatusBadRequest},
+		{http.MethodPost, `{"pair": "XETHZEUR", "amount": 25, "timestamp": 1665400010}`, "webhook_secret", http.StatusUnprocessableEntity},
+		{http.MethodPost, `{"pair": "XXBTZEUR", "amount": 75, "timestamp": 1665400010}`, "webhook_secret", http.StatusUnprocessableEntity},
+		{http.MethodPost, `{"pair": "XXBTZEUR", "amount": -5, "timestamp": 1665400010}`, "webhook_secret", http.StatusUnprocessableEntity},
+		{http.MethodPost, `{"pair": "XXBTZEUR", "amount": 25, "timestamp": 1665300000}`, "webhook_secret", http.StatusUnprocessableEntity},
+	}
+
+	for _, c := range cases {
+		recorder, received := send(t, c.method, c.body, Sign([]byte(c.body), c.secret))
+
+		if recorder.Code != c.code || len(received) != 0 {
+			t.Errorf("The %s response code is %d instead of %d : %s", c.body, recorder.Code, c.code, recorder.Body.String())
+		}
+	}
+}
+
+func TestHandlerReplayRejected(t *testing.T) {
+	body := `{"pair": "XXBTZEUR", "amount": 25, "timestamp": 1665400010}`
+	signature := Sign([]byte(body), "webhook_secret")
+	clock := now
+	h := newHandler(config, nil, func() time.Time { return clock })
+
+	if recorder, received := sendTo(h, http.MethodPost, body, signature); recorder.Code != http.StatusAccepted || len(received) != 1 {
+		t.Fatalf("The first request should be accepted : %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	if recorder, received := sendTo(h, http.MethodPost, body, signature); recorder.Code != http.StatusConflict || len(received) != 0 {
+		t.Errorf("The replayed request should be rejected : %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	// Once the request is too old to be accepted, the replay is rejected as too old and its signature forgotten
+	clock = now.Add(maxAge + time.Minute)
+	if recorder, _ := sendTo(h, http.MethodPost, body, signature); recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("The expired replay should be rejected as too old : %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	body = `{"pair": "XXBTZEUR", "amount": 25, "timestamp": 1665400370}`
+	if recorder, _ := sendTo(h, http.MethodPost, body, Sign([]byte(body), "webhook_secret")); recorder.Code != http.StatusAccepted || len(h.signatures) != 1 {
+		t.Errorf("Only the new request signature should be kept : %d %v", recorder.Code, h.signatures)
+	}
+}